@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/carlosranoya/inject"
+	"github.com/carlosranoya/inject/internal/tags"
+)
+
+// entries maps the key of each entry of a config section to a description of
+// what it sets, compared between profiles.
+type entries map[string]map[string]string
+
+// diffConfigs lists the differences between two configs, sorted by entry:
+// "+" for entries only in b, "-" for entries only in a and "~" for settings
+// that changed, like the injectable bound to an interface.
+func diffConfigs(a, b *inject.Config) []string {
+	var changes []string
+	sections := []struct {
+		name string
+		read func(*inject.Config) entries
+	}{
+		{"interface", interfaceEntries},
+		{"injectable", injectableEntries},
+		{"factory", factoryEntries},
+	}
+	for _, section := range sections {
+		before, after := section.read(a), section.read(b)
+		for _, key := range sortedKeys(before, after) {
+			old, inA := before[key]
+			new, inB := after[key]
+			switch {
+			case !inB:
+				changes = append(changes, fmt.Sprintf("- %s %s", section.name, key))
+			case !inA:
+				changes = append(changes, fmt.Sprintf("+ %s %s", section.name, key))
+			default:
+				for _, setting := range sortedKeys(old, new) {
+					if old[setting] != new[setting] {
+						changes = append(changes, fmt.Sprintf("~ %s %s %s: %s -> %s", section.name, key, setting, orNone(old[setting]), orNone(new[setting])))
+					}
+				}
+			}
+		}
+	}
+	return changes
+}
+
+func interfaceEntries(c *inject.Config) entries {
+	result := entries{}
+	for _, inter := range c.Interfaces {
+		key := inter.GetPath()
+		if inter.Named != "" {
+			key += " named " + inter.Named
+		}
+		injectable := inter.Injectable
+		if inj, _ := c.BoundInjectable(&inter); inj != nil {
+			injectable = inj.GetPath()
+		}
+		result[key] = map[string]string{"injectable": injectable, "decorators": strings.Join(inter.Decorators, ", ")}
+	}
+	return result
+}
+
+func injectableEntries(c *inject.Config) entries {
+	result := entries{}
+	for _, inj := range c.Injectables {
+		result[inj.GetPath()] = map[string]string{
+			"mode":    inj.InjectMode,
+			"factory": inj.Factory,
+			"params":  flow(inj.Params),
+		}
+	}
+	return result
+}
+
+func factoryEntries(c *inject.Config) entries {
+	result := entries{}
+	for _, f := range c.Factories {
+		result[f.GetPath()] = map[string]string{"is-singleton": fmt.Sprint(f.IsSingleton)}
+	}
+	return result
+}
+
+// flow writes params as compact JSON, with sorted keys.
+func flow(params any) string {
+	if params == nil {
+		return ""
+	}
+	content, err := json.Marshal(tags.JSONValue(params))
+	if err != nil {
+		return fmt.Sprint(params)
+	}
+	return string(content)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	var keys []string
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+// Command inject checks and compares inject config files, working on the yaml
+// model alone, without running the program using them.
+//
+// Usage:
+//
+//	inject lint <config>...        report schema errors, unknown keys and dangling references
+//	inject diff <a.yaml> <b.yaml>  list the binding differences between two profiles
+//	inject graph [-json] <config>  write the bindings of a config as a DOT (or JSON) graph
+//
+// lint and diff exit with status 1 when they find problems or differences.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/carlosranoya/inject"
+)
+
+const usage = `usage:
+  inject lint <config>...
+  inject diff <a.yaml> <b.yaml>
+  inject graph [-json] <config>
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a subcommand and returns the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "lint":
+		return lint(args[1:], stdout, stderr)
+	case "diff":
+		return diff(args[1:], stdout, stderr)
+	case "graph":
+		return graph(args[1:], stdout, stderr)
+	}
+	fmt.Fprintf(stderr, "inject: unknown command %q\n%s", args[0], usage)
+	return 2
+}
+
+func lint(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	status := 0
+	for _, file := range args {
+		config, err := inject.ReadConfig(file)
+		if err == nil {
+			err = config.Lint()
+		}
+		if err == nil {
+			continue
+		}
+		status = 1
+		var errs inject.ValidationErrors
+		var configError *inject.ConfigError
+		switch {
+		case errors.As(err, &errs):
+			for _, e := range errs {
+				if errors.As(e, &configError) {
+					fmt.Fprintln(stdout, e)
+				} else {
+					fmt.Fprintf(stdout, "%s: %v\n", file, e)
+				}
+			}
+		case errors.As(err, &configError):
+			fmt.Fprintln(stdout, err)
+		default:
+			fmt.Fprintf(stdout, "%s: %v\n", file, err)
+		}
+	}
+	return status
+}
+
+func diff(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 2 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	a, err := inject.ReadConfig(args[0])
+	if err != nil {
+		fmt.Fprintln(stderr, "inject:", err)
+		return 2
+	}
+	b, err := inject.ReadConfig(args[1])
+	if err != nil {
+		fmt.Fprintln(stderr, "inject:", err)
+		return 2
+	}
+
+	changes := diffConfigs(a, b)
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func graph(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "write JSON instead of DOT")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	config, err := inject.ReadConfig(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, "inject:", err)
+		return 2
+	}
+	g := config.Graph()
+	if *asJSON {
+		err = g.WriteJSON(stdout)
+	} else {
+		err = g.WriteDOT(stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "inject:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const files = "../../test_files/"
+
+func runCommand(args ...string) (int, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, &stdout, &stderr)
+	return status, stdout.String() + stderr.String()
+}
+
+func TestLint(t *testing.T) {
+
+	status, output := runCommand("lint", files+"injection-config.dev.yaml")
+	if status != 0 || output != "" {
+		t.Fatalf("lint. Expected no problems, got %d: %s", status, output)
+	}
+
+	status, output = runCommand("lint", files+"config_strict.yaml", files+"config_lint.yaml")
+	if status != 1 {
+		t.Fatalf("lint. Expected status 1, got %d", status)
+	}
+	for _, line := range []string{
+		files + `config_strict.yaml:6: unknown key "is_singleton"`,
+		files + `config_lint.yaml: interfaces: "inject.iMessagePrinter" refers to injectable "messagePrinterX"`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Fatalf("lint. Expected %q, got\n%s", line, output)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+
+	status, output := runCommand("diff", files+"injection-config.dev.yaml", files+"injection-config.prod.yaml")
+	if status != 1 {
+		t.Fatalf("diff. Expected status 1, got %d", status)
+	}
+	expected := []string{
+		"~ interface inject.iMessagePrinter injectable: inject.messagePrinterD -> inject.messagePrinterB",
+		"~ injectable inject.messagePrinterB mode: auto -> (none)",
+		"- injectable inject.messagePrinterD",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Fatalf("diff. Expected %q, got\n%s", line, output)
+		}
+	}
+
+	status, output = runCommand("diff", files+"injection-config.dev.yaml", files+"injection-config.dev.yaml")
+	if status != 0 || output != "" {
+		t.Fatalf("diff. Expected no differences, got %d: %s", status, output)
+	}
+}
+
+func TestGraph(t *testing.T) {
+
+	status, output := runCommand("graph", files+"config_tags.yaml")
+	line := `"inject.iMessagePrinter" -> "inject.messagePrinterB" [label="secondary", style=dashed];`
+	if status != 0 || !strings.Contains(output, line) {
+		t.Fatalf("graph. Expected %s, got %d:\n%s", line, status, output)
+	}
+
+	status, output = runCommand("graph", "-json", files+"config_tags.yaml")
+	if status != 0 || !strings.Contains(output, `"kind": "binds"`) {
+		t.Fatalf("graph -json. Expected a JSON graph, got %d:\n%s", status, output)
+	}
+
+	if status, _ := runCommand("graph"); status != 2 {
+		t.Fatalf("graph. Expected status 2 without config, got %d", status)
+	}
+}
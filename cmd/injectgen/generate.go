@@ -0,0 +1,644 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/carlosranoya/inject"
+	"github.com/carlosranoya/inject/internal/tags"
+)
+
+const injectImport = `"github.com/carlosranoya/inject"`
+
+type options struct {
+	configFile string
+	profile    string
+	roots      []string // other structs to generate constructors for
+	buildTags  string
+}
+
+// builder is a generated function building one struct, with the params of an
+// injectables entry when it is reached through a binding.
+type builder struct {
+	name  string
+	t     *structType
+	entry *inject.InjectableDescription
+}
+
+type generator struct {
+	pkg     *packageInfo
+	config  *inject.Config
+	suffix  string
+	imports map[string]bool
+	funcs   bytes.Buffer
+	names   map[string]bool
+	pending []builder
+	temps   int
+	errs    []string
+}
+
+// generate returns the formatted source of the constructors wiring pkg as
+// described by config.
+func generate(pkg *packageInfo, config *inject.Config, opts options) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		config:  config,
+		suffix:  exportedName(opts.profile),
+		imports: map[string]bool{injectImport: true},
+		names:   map[string]bool{},
+	}
+
+	var paths []inject.ComponentPath
+	for _, f := range config.Factories {
+		paths = append(paths, f.ComponentPath)
+	}
+	for _, i := range config.Injectables {
+		paths = append(paths, i.ComponentPath)
+	}
+	for _, path := range paths {
+		if st, ok := pkg.structs[path.Name]; ok && g.local(path) {
+			g.structConstructor(st)
+		}
+	}
+	for _, name := range opts.roots {
+		if st, ok := pkg.structs[name]; ok {
+			g.structConstructor(st)
+		} else {
+			g.errorf("-type: no struct named %s in package %s", name, pkg.name)
+		}
+	}
+
+	for _, i := range config.Interfaces {
+		if g.local(i.ComponentPath) && i.Named == "" {
+			g.interfaceConstructor(i)
+		}
+	}
+
+	for len(g.pending) > 0 {
+		b := g.pending[0]
+		g.pending = g.pending[1:]
+		g.build(b)
+	}
+
+	if len(g.errs) > 0 {
+		return nil, errors.New(strings.Join(g.errs, "\n"))
+	}
+
+	if bytes.Contains(g.funcs.Bytes(), []byte("fmt.")) {
+		g.imports[`"fmt"`] = true
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s from %s; DO NOT EDIT.\n\n", generatedHeader, opts.configFile)
+	if opts.buildTags != "" {
+		fmt.Fprintf(&out, "//go:build %s\n\n", opts.buildTags)
+	}
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg.name)
+	var std, other []string
+	for spec := range g.imports {
+		importPath := spec[strings.Index(spec, `"`):]
+		if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for _, spec := range std {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		out.WriteString("\n")
+	}
+	for _, spec := range other {
+		fmt.Fprintf(&out, "\t%s\n", spec)
+	}
+	out.WriteString(")\n")
+	out.Write(g.funcs.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return source, nil
+}
+
+func (g *generator) errorf(format string, args ...any) {
+	g.errs = append(g.errs, fmt.Sprintf(format, args...))
+}
+
+// local reports whether a config entry names a type of the wired package, by
+// short package name or import path.
+func (g *generator) local(path inject.ComponentPath) bool {
+	return path.Package == g.pkg.name || strings.HasSuffix(path.Package, "/"+g.pkg.name)
+}
+
+// funcName names a generated function after a type, keeping it unexported
+// when the type is.
+func (g *generator) funcName(prefix string, typeName string) string {
+	name := exportedName(prefix) + exportedName(typeName) + g.suffix
+	if !ast.IsExported(typeName) {
+		name = prefix + exportedName(typeName) + g.suffix
+	}
+	return name
+}
+
+// structConstructor writes the constructor of st, mirroring Instanciate.
+func (g *generator) structConstructor(st *structType) {
+	name := g.funcName("new", st.name)
+	if g.names[name] {
+		return
+	}
+	g.names[name] = true
+	build := g.builder(st, nil)
+
+	fmt.Fprintf(&g.funcs, "\n// %s builds a %s like inject.Instanciate[%s]().\n", name, st.name, st.name)
+	fmt.Fprintf(&g.funcs, "func %s() (*%s, error) {\n", name, st.name)
+	g.writeValidated(build)
+}
+
+// interfaceConstructor writes the constructor of the interface bound by entry,
+// mirroring InstaciateInjected.
+func (g *generator) interfaceConstructor(entry inject.InterfaceDescription) {
+	name := g.funcName("new", entry.Name)
+	if g.names[name] {
+		return
+	}
+	if !g.pkg.interfaces[entry.Name] {
+		g.errorf("interfaces: no interface named %s in package %s", entry.Name, g.pkg.name)
+		return
+	}
+	g.names[name] = true
+	build, err := g.binding(entry.Name, "")
+	if err != nil || build == "" {
+		if err == nil {
+			err = errors.New("not bound to an injectable of the package")
+		}
+		g.errorf("interfaces: %s: %v", entry.GetPath(), err)
+		return
+	}
+
+	fmt.Fprintf(&g.funcs, "\n// %s builds the %s bound by the config like inject.InstaciateInjected[%s]().\n", name, entry.Name, entry.Name)
+	fmt.Fprintf(&g.funcs, "func %s() (%s, error) {\n", name, entry.Name)
+	g.writeValidated(build)
+}
+
+func (g *generator) writeValidated(build string) {
+	fmt.Fprintf(&g.funcs, "\tv, err := %s()\n", build)
+	fmt.Fprintf(&g.funcs, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(&g.funcs, "\tif err := inject.ValidateStruct(v); err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(&g.funcs, "\treturn v, nil\n}\n")
+}
+
+// builder returns the name of the function building st, with the params of
+// entry when it has any, queueing it the first time.
+func (g *generator) builder(st *structType, entry *inject.InjectableDescription) string {
+	prefix := "build"
+	if entry != nil && entry.Params != nil {
+		prefix = "bind"
+	} else {
+		entry = nil
+	}
+	name := prefix + exportedName(st.name) + g.suffix
+	if !g.names[name] {
+		g.names[name] = true
+		g.pending = append(g.pending, builder{name: name, t: st, entry: entry})
+	}
+	return name
+}
+
+// binding returns the builder of the injectable bound to the type named
+// typeName, following the interfaces entry with the given named key, or the
+// injectables entry of the type itself in mode auto. An empty name means
+// nothing is bound.
+func (g *generator) binding(typeName string, named string) (string, error) {
+	var inter *inject.InterfaceDescription
+	for i := range g.config.Interfaces {
+		entry := &g.config.Interfaces[i]
+		if entry.Name != typeName || !g.local(entry.ComponentPath) {
+			continue
+		}
+		if entry.Named == named {
+			inter = entry
+			break
+		}
+		if named == "" && inter == nil {
+			inter = entry
+		}
+	}
+	if named != "" && inter == nil {
+		return "", fmt.Errorf("interfaces: no entry for %s named %q", typeName, named)
+	}
+	if inter != nil && len(inter.Decorators) > 0 {
+		// decorators are functions registered at run time with AddDecorator
+		return "", fmt.Errorf("interfaces: %s lists decorators, which generated wiring does not support", inter.GetPath())
+	}
+
+	var injectable *inject.InjectableDescription
+	if inter != nil {
+		for i := range g.config.Injectables {
+			entry := &g.config.Injectables[i]
+			if entry.Name == inter.Injectable || entry.GetPath() == inter.Injectable {
+				injectable = entry
+				break
+			}
+		}
+	}
+	if injectable == nil {
+		for i := range g.config.Injectables {
+			entry := &g.config.Injectables[i]
+			if entry.Name == typeName && g.local(entry.ComponentPath) && entry.InjectMode == "auto" {
+				injectable = entry
+				break
+			}
+		}
+	}
+	if injectable == nil {
+		return "", nil
+	}
+
+	st, ok := g.pkg.structs[injectable.Name]
+	if !ok || !g.local(injectable.ComponentPath) {
+		return "", fmt.Errorf("injectable %s is not a struct of package %s", injectable.GetPath(), g.pkg.name)
+	}
+	return g.builder(st, injectable), nil
+}
+
+// build writes the function of b, setting each field layer by layer like the
+// inject package: value tag, config binding, params and env variable.
+func (g *generator) build(b builder) {
+	var params map[int]any
+	if b.entry != nil {
+		var err error
+		if params, err = paramsByField(b.t, b.entry.Params); err != nil {
+			g.errorf("injectables: %s: %v", b.entry.GetPath(), err)
+			return
+		}
+	}
+
+	fmt.Fprintf(&g.funcs, "\nfunc %s() (*%s, error) {\n\tv := &%s{}\n", b.name, b.t.name, b.t.name)
+	for i, f := range b.t.fields {
+		param, hasParam := params[i]
+		if f.tag.Get("inject") == "" && f.tag.Get("env") == "" && !hasParam {
+			continue
+		}
+		if err := g.field(b.t, f, param, hasParam); err != nil {
+			g.errorf("%s.%s: %v", b.t.name, f.name, err)
+		}
+	}
+	g.funcs.WriteString("\treturn v, nil\n}\n")
+}
+
+func (g *generator) field(st *structType, f field, param any, hasParam bool) error {
+	tag, err := tags.ParseInject(f.tag.Get("inject"))
+	if err == nil {
+		err = tag.Check()
+	}
+	if err != nil {
+		return err
+	}
+	if tag.Lazy || (tag.Scope != "" && tag.Scope != tags.ScopeTransient) {
+		return errors.New("lazy fields and request or singleton scopes are not supported, use the inject package")
+	}
+
+	target := "v." + f.name
+	value := f.tag.Get("value")
+	settable := f.exported || tag.Private
+	unsettable := errors.New(`unexported field can't be set, use the private option (inject:"name,private")`)
+
+	// 1. value tag and nested structs
+	nested, pointer := g.nestedStruct(f.expr)
+	switch {
+	case nested != nil && !pointer:
+		if value != "" {
+			return errors.New("JSON values for nested structs are not supported, use the inject package")
+		}
+		g.call(f, g.builder(nested, nil), "*")
+	case nested != nil:
+		if value == "nil" || value == "null" {
+			break
+		}
+		if value != "" {
+			return errors.New("JSON values for nested structs are not supported, use the inject package")
+		}
+		if !settable {
+			return unsettable
+		}
+		g.call(f, g.builder(nested, nil), "")
+	case value != "":
+		if !settable {
+			return unsettable
+		}
+		if ident, ok := f.expr.(*ast.Ident); ok && isBuiltin(ident.Name) && hasParam {
+			// overridden by the param, but still checked
+			if _, err := textLiteral(value, ident.Name); err != nil {
+				return err
+			}
+			break
+		}
+		if err := g.setText(st, f, value); err != nil {
+			return err
+		}
+	case isExternalPointer(f.expr):
+		return errors.New("pointers to types of other packages are not supported, use the inject package")
+	}
+
+	// 2. config binding
+	if tag.Struct {
+		bound := typeName(f.expr)
+		build, err := g.binding(bound, tag.Name)
+		if err != nil && !tag.Optional {
+			return err
+		}
+		if build != "" {
+			if !settable {
+				return unsettable
+			}
+			deref := ""
+			if _, ok := f.expr.(*ast.Ident); ok && !g.pkg.interfaces[bound] {
+				deref = "*"
+			}
+			g.call(f, build, deref)
+		}
+	}
+
+	// 3. params
+	if hasParam {
+		if !settable {
+			return unsettable
+		}
+		if _, ok := param.(map[any]any); ok && nested != nil {
+			return errors.New("nested params are not supported, use the inject package")
+		}
+		if err := g.setParam(st, f, param); err != nil {
+			return fmt.Errorf("params: %w", err)
+		}
+	}
+
+	// 4. env variable
+	if name := f.tag.Get("env"); name != "" {
+		if !settable {
+			return unsettable
+		}
+		g.imports[`"os"`] = true
+		g.addImports(st, f.expr)
+		if ident, ok := f.expr.(*ast.Ident); ok && ident.Name == "string" {
+			fmt.Fprintf(&g.funcs, "\tif text, ok := os.LookupEnv(%q); ok {\n\t\t%s = text\n\t}\n", name, target)
+			return nil
+		}
+		x := g.temp()
+		fmt.Fprintf(&g.funcs, "\tif text, ok := os.LookupEnv(%q); ok {\n", name)
+		fmt.Fprintf(&g.funcs, "\t\t%s, err := inject.ParseText[%s](text)\n", x, types.ExprString(f.expr))
+		fmt.Fprintf(&g.funcs, "\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"field %s: env %s: %%w\", err)\n\t\t}\n", f.name, name)
+		fmt.Fprintf(&g.funcs, "\t\t%s = %s\n\t}\n", target, x)
+	}
+	return nil
+}
+
+// nestedStruct returns the struct of the package a field of type expr is, or
+// points to, when it is filled field by field instead of decoded.
+func (g *generator) nestedStruct(expr ast.Expr) (st *structType, pointer bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+		pointer = true
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || g.pkg.decoders[ident.Name] {
+		return nil, false
+	}
+	return g.pkg.structs[ident.Name], pointer
+}
+
+// isExternalPointer reports whether expr points to a type of another package,
+// which may be either decoded or filled field by field.
+func isExternalPointer(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		_, ok := star.X.(*ast.SelectorExpr)
+		return ok
+	}
+	return false
+}
+
+// call sets a field to the result of a generated builder.
+func (g *generator) call(f field, build string, deref string) {
+	x := g.temp()
+	fmt.Fprintf(&g.funcs, "\t%s, err := %s()\n", x, build)
+	fmt.Fprintf(&g.funcs, "\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"field %s: %%w\", err)\n\t}\n", f.name)
+	fmt.Fprintf(&g.funcs, "\tv.%s = %s%s\n", f.name, deref, x)
+}
+
+// setText sets a field from the text of a value tag: a literal for builtin
+// types, and inject.ParseText for the others.
+func (g *generator) setText(st *structType, f field, text string) error {
+	if ident, ok := f.expr.(*ast.Ident); ok && isBuiltin(ident.Name) {
+		literal, err := textLiteral(text, ident.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&g.funcs, "\tv.%s = %s\n", f.name, literal)
+		return nil
+	}
+	g.addImports(st, f.expr)
+	x := g.temp()
+	fmt.Fprintf(&g.funcs, "\t%s, err := inject.ParseText[%s](%s)\n", x, types.ExprString(f.expr), strconv.Quote(text))
+	fmt.Fprintf(&g.funcs, "\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"field %s: %%w\", err)\n\t}\n", f.name)
+	fmt.Fprintf(&g.funcs, "\tv.%s = %s\n", f.name, x)
+	return nil
+}
+
+// setParam sets a field from a config param, which is written as a literal
+// for builtin types or as text (JSON for collections) for the others.
+func (g *generator) setParam(st *structType, f field, param any) error {
+	ident, builtin := f.expr.(*ast.Ident)
+	builtin = builtin && isBuiltin(ident.Name)
+	if !builtin {
+		if text, ok := param.(string); ok {
+			return g.setText(st, f, text)
+		}
+		content, err := json.Marshal(tags.JSONValue(param))
+		if err != nil {
+			return err
+		}
+		return g.setText(st, f, string(content))
+	}
+
+	var literal string
+	var err error
+	switch p := param.(type) {
+	case nil:
+		literal = zeroLiteral(ident.Name)
+	case string:
+		literal, err = textLiteral(p, ident.Name)
+	case bool:
+		switch ident.Name {
+		case "bool", "any":
+			literal = strconv.FormatBool(p)
+		default:
+			err = fmt.Errorf("cannot use bool as %s", ident.Name)
+		}
+	case int, int64, uint64, float64:
+		literal, err = numberLiteral(p, ident.Name)
+	default:
+		err = fmt.Errorf("cannot use %T as %s", param, ident.Name)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.funcs, "\tv.%s = %s\n", f.name, literal)
+	return nil
+}
+
+func (g *generator) temp() string {
+	g.temps++
+	return "x" + strconv.Itoa(g.temps)
+}
+
+// addImports adds the imports of the packages used by a field type.
+func (g *generator) addImports(st *structType, expr ast.Expr) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				if spec, ok := st.imports[pkg.Name]; ok {
+					g.imports[spec] = true
+				}
+			}
+		}
+		return true
+	})
+}
+
+// paramsByField assigns params to fields like the inject package, which
+// shares its key matching.
+func paramsByField(st *structType, params any) (map[int]any, error) {
+	fields := make([]tags.Field, len(st.fields))
+	for i, f := range st.fields {
+		fields[i] = tags.Field{Name: f.name, Tag: f.tag}
+	}
+	return tags.ParamsByField(fields, st.name, params)
+}
+
+var builtinBits = map[string]int{
+	"int": strconv.IntSize, "int8": 8, "int16": 16, "int32": 32, "int64": 64, "rune": 32,
+	"uint": strconv.IntSize, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uintptr": strconv.IntSize, "byte": 8,
+	"float32": 32, "float64": 64,
+}
+
+func isBuiltin(name string) bool {
+	_, number := builtinBits[name]
+	return number || name == "string" || name == "bool" || name == "any"
+}
+
+// textLiteral parses text like the inject package parses a value tag into a
+// builtin type, and returns it as a Go literal.
+func textLiteral(text string, typeName string) (string, error) {
+	value := strings.TrimSpace(text)
+	bits := builtinBits[typeName]
+	switch typeName {
+	case "string", "any":
+		return strconv.Quote(text), nil
+	case "bool":
+		value = strings.ToLower(value)
+		return strconv.FormatBool(!(value == "false" || value == "0" || value == "nil" || value == "none" || value == "null" || value == "")), nil
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		n, err := strconv.ParseInt(value, 10, bits)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse %q as %s", text, typeName)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		n, err := strconv.ParseUint(value, 10, bits)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse %q as %s", text, typeName)
+		}
+		return strconv.FormatUint(n, 10), nil
+	case "float32", "float64":
+		n, err := strconv.ParseFloat(value, bits)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse %q as %s", text, typeName)
+		}
+		return floatLiteral(n, bits), nil
+	}
+	return "", fmt.Errorf("cannot parse %q as %s", text, typeName)
+}
+
+// numberLiteral converts a yaml number like the inject package converts a
+// param into a builtin type, and returns it as a Go literal.
+func numberLiteral(number any, typeName string) (string, error) {
+	var text string
+	isFloat := false
+	switch n := number.(type) {
+	case int:
+		text = strconv.Itoa(n)
+	case int64:
+		text = strconv.FormatInt(n, 10)
+	case uint64:
+		text = strconv.FormatUint(n, 10)
+	case float64:
+		text = strconv.FormatFloat(n, 'f', -1, 64)
+		isFloat = true
+	}
+	switch typeName {
+	case "string":
+		return "", fmt.Errorf("cannot use %T as string", number)
+	case "bool":
+		return strconv.FormatBool(text != "0"), nil
+	case "any":
+		if isFloat {
+			return "float64(" + text + ")", nil
+		}
+		return text, nil
+	}
+	literal, err := textLiteral(text, typeName)
+	if err != nil {
+		return "", fmt.Errorf("cannot use %s as %s", text, typeName)
+	}
+	return literal, nil
+}
+
+func floatLiteral(f float64, bits int) string {
+	text := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(text, ".eEIN") {
+		text += ".0"
+	}
+	return text
+}
+
+func zeroLiteral(typeName string) string {
+	switch typeName {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "any":
+		return "nil"
+	}
+	return "0"
+}
+
+// exportedName turns a name like dev or my-profile into Dev or MyProfile.
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '-' || r == '_' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
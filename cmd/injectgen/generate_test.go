@@ -0,0 +1,121 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlosranoya/inject"
+)
+
+const wiringDir = "../../internal/testpkg/wiring"
+
+// TestGenerateWiring checks the generated files of the wiring package are up
+// to date. Its own tests compare them with the inject package.
+func TestGenerateWiring(t *testing.T) {
+
+	pkg, err := loadPackage(wiringDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range []string{"dev", "prod"} {
+		file := "wiring." + profile + ".yaml"
+		config, err := inject.ReadConfig(filepath.Join(wiringDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		source, err := generate(pkg, config, options{configFile: file, profile: profile})
+		if err != nil {
+			t.Fatalf("generate(%s). Expected no error, got %v", file, err)
+		}
+		expected, err := os.ReadFile(filepath.Join(wiringDir, "inject_"+profile+"_gen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(source) != string(expected) {
+			t.Fatalf("generate(%s). Generated code is out of date, run go generate in %s", file, wiringDir)
+		}
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+
+	source := `package app
+
+type Printer interface{ Print() }
+
+type Service struct {
+	Lazy    func() Printer ` + "`inject:\"printer,lazy\"`" + `
+	Count   int            ` + "`inject:\"count\" value:\"many\"`" + `
+	Scoped  Printer        ` + "`inject:\"scoped,struct,scope=singleton\"`" + `
+	name    string         ` + "`inject:\"name\" value:\"app\"`" + `
+	Unknown Printer        ` + "`inject:\"unknown,strcut\"`" + `
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "app.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packageInfo{name: "app", structs: map[string]*structType{}, interfaces: map[string]bool{}, decoders: map[string]bool{}}
+	pkg.addFile(file)
+
+	config := &inject.Config{Injectables: []inject.InjectableDescription{{ComponentPath: inject.ComponentPath{Name: "Service", Package: "app"}}}}
+	_, err = generate(pkg, config, options{})
+	if err == nil {
+		t.Fatal("generate(). Expected errors for unsupported fields")
+	}
+	for _, message := range []string{
+		"Service.Lazy: lazy fields",
+		`Service.Count: cannot parse "many" as int`,
+		"Service.Scoped: lazy fields and request or singleton scopes",
+		"Service.name: unexported field",
+		`Service.Unknown: inject tag: unknown option "strcut"`,
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Fatalf("generate(). Expected %q, got %v", message, err)
+		}
+	}
+}
+
+func TestProfileName(t *testing.T) {
+	for file, expected := range map[string]string{
+		"injection-config.dev.yaml": "dev",
+		"configs/app.prod.yml":      "prod",
+		"config.yaml":               "",
+	} {
+		if name := profileName(file); name != expected {
+			t.Fatalf("profileName(%s). Expected %q, got %q", file, expected, name)
+		}
+	}
+}
+
+func TestLoadPackageBuildConstraints(t *testing.T) {
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module app\n",
+		"app.go":      "package app\n\ntype Printer interface{ Print() }\n",
+		"dev.go":      "//go:build !prod\n\npackage app\n\ntype DevPrinter struct{}\n",
+		"prod.go":     "//go:build prod\n\npackage app\n\ntype ProdPrinter struct{}\n",
+		"app_test.go": "package app_test\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for tags, expected := range map[string]string{"": "DevPrinter", "prod && !debug": "ProdPrinter"} {
+		pkg, err := loadPackage(dir, tags)
+		if err != nil {
+			t.Fatalf("loadPackage(%q). Expected no error, got %v", tags, err)
+		}
+		if len(pkg.structs) != 1 || pkg.structs[expected] == nil || !pkg.interfaces["Printer"] {
+			t.Fatalf("loadPackage(%q). Expected %s and Printer, got %v", tags, expected, pkg.structs)
+		}
+	}
+}
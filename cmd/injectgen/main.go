@@ -0,0 +1,98 @@
+// Command injectgen generates plain Go constructors wiring the structs of a
+// package from an inject config file and the inject, value and env tags, so
+// builds can skip the reflection path of the inject package.
+//
+// It is usually run from a go:generate directive, once per profile:
+//
+//	//go:generate go run github.com/carlosranoya/inject/cmd/injectgen -config injection-config.dev.yaml
+//	//go:generate go run github.com/carlosranoya/inject/cmd/injectgen -config injection-config.prod.yaml
+//
+// For a profile named prod, it writes inject_prod_gen.go with:
+//
+//   - NewTProd() (*T, error) for each struct T of the package declared in the
+//     injectables or factories sections, or named by -type. It builds the same
+//     object as inject.Instanciate[T]().
+//   - NewIProd() (I, error) for each interface I bound in the interfaces
+//     section, like inject.InstaciateInjected[I]().
+//
+// Unexported types get unexported constructors (newTProd). Lazy fields,
+// request and singleton scopes, nested params and decorators are reported as
+// errors. Injection methods are registered at run time with
+// inject.AddInjectionMethods, which the generator can't see: the constructors
+// don't call them, so types relying on them must call them on the result.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/carlosranoya/inject"
+)
+
+func main() {
+	configFile := flag.String("config", "", "config file to generate the wiring from (required)")
+	profile := flag.String("profile", "", "profile name added to the generated names (default: taken from the config file name, like dev for injection-config.dev.yaml)")
+	dir := flag.String("dir", ".", "directory of the package to wire")
+	output := flag.String("o", "", "output file, relative to -dir (default: inject_<profile>_gen.go)")
+	types := flag.String("type", "", "comma separated list of other structs to generate constructors for")
+	buildTags := flag.String("tags", "", "build constraint added to the output file, like prod; its tags also select the files of the package")
+	flag.Parse()
+
+	if *configFile == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *profile == "" {
+		*profile = profileName(*configFile)
+	}
+	if *output == "" {
+		*output = "inject_gen.go"
+		if *profile != "" {
+			*output = "inject_" + *profile + "_gen.go"
+		}
+	}
+
+	if err := run(*configFile, *dir, *output, *profile, *types, *buildTags); err != nil {
+		fmt.Fprintln(os.Stderr, "injectgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(configFile, dir, output, profile, types, buildTags string) error {
+	config, err := inject.ReadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	pkg, err := loadPackage(dir, buildTags)
+	if err != nil {
+		return err
+	}
+
+	var roots []string
+	if types != "" {
+		roots = strings.Split(types, ",")
+	}
+	source, err := generate(pkg, config, options{
+		configFile: filepath.Base(configFile),
+		profile:    profile,
+		roots:      roots,
+		buildTags:  buildTags,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), source, 0o644)
+}
+
+// profileName takes the profile from config file names like
+// injection-config.dev.yaml.
+func profileName(configFile string) string {
+	parts := strings.Split(filepath.Base(configFile), ".")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const generatedHeader = "// Code generated by injectgen"
+
+// field is a field of a struct declared in the wired package.
+type field struct {
+	name     string
+	expr     ast.Expr
+	tag      reflect.StructTag
+	exported bool
+}
+
+// structType is a struct declared in the wired package.
+type structType struct {
+	name    string
+	fields  []field
+	imports map[string]string // import specs of its file, by package name
+}
+
+// packageInfo holds the declarations of the wired package.
+type packageInfo struct {
+	name       string
+	structs    map[string]*structType
+	interfaces map[string]bool
+	decoders   map[string]bool // types with UnmarshalText or UnmarshalJSON methods
+}
+
+// loadPackage parses the package in dir, skipping tests and files generated
+// by injectgen. Files are selected by their build constraints, as when
+// building for the current platform with the tags named by buildTags, the
+// constraint of the output file.
+func loadPackage(dir string, buildTags string) (*packageInfo, error) {
+	ctx := build.Default
+	tags, err := constraintTags(buildTags)
+	if err != nil {
+		return nil, err
+	}
+	ctx.BuildTags = append(ctx.BuildTags, tags...)
+	bp, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &packageInfo{
+		name:       bp.Name,
+		structs:    map[string]*structType{},
+		interfaces: map[string]bool{},
+		decoders:   map[string]bool{},
+	}
+	fset := token.NewFileSet()
+	files := append(append([]string(nil), bp.GoFiles...), bp.CgoFiles...)
+	sort.Strings(files)
+	for _, name := range files {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if isGenerated(file) {
+			continue
+		}
+		info.addFile(file)
+	}
+	return info, nil
+}
+
+// constraintTags returns the tags a build constraint like "prod && !debug"
+// requires, the ones not negated.
+func constraintTags(expr string) ([]string, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	c, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return nil, fmt.Errorf("-tags: %w", err)
+	}
+	var tags []string
+	var walk func(constraint.Expr)
+	walk = func(e constraint.Expr) {
+		switch e := e.(type) {
+		case *constraint.TagExpr:
+			tags = append(tags, e.Tag)
+		case *constraint.AndExpr:
+			walk(e.X)
+			walk(e.Y)
+		case *constraint.OrExpr:
+			walk(e.X)
+			walk(e.Y)
+		}
+	}
+	walk(c)
+	return tags, nil
+}
+
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, generatedHeader) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (info *packageInfo) addFile(file *ast.File) {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			imports[spec.Name.Name] = spec.Name.Name + " " + spec.Path.Value
+		} else {
+			imports[importName(importPath)] = spec.Path.Value
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			if name := decl.Name.Name; name == "UnmarshalText" || name == "UnmarshalJSON" {
+				info.decoders[typeName(decl.Recv.List[0].Type)] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				switch t := spec.Type.(type) {
+				case *ast.InterfaceType:
+					info.interfaces[spec.Name.Name] = true
+				case *ast.StructType:
+					info.structs[spec.Name.Name] = newStructType(spec.Name.Name, t, imports)
+				}
+			}
+		}
+	}
+}
+
+func newStructType(name string, t *ast.StructType, imports map[string]string) *structType {
+	st := &structType{name: name, imports: imports}
+	for _, f := range t.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			value, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(value)
+		}
+		names := f.Names
+		if len(names) == 0 {
+			// embedded fields are named after their type
+			names = []*ast.Ident{ast.NewIdent(typeName(f.Type))}
+		}
+		for _, n := range names {
+			st.fields = append(st.fields, field{name: n.Name, expr: f.Type, tag: tag, exported: ast.IsExported(n.Name)})
+		}
+	}
+	return st
+}
+
+// typeName returns the name of a possibly pointer or qualified type.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// importName guesses the package name of an import path: its last element,
+// skipping major version suffixes like v2 and extensions like .v2.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return strings.ReplaceAll(name, "-", "_")
+}
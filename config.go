@@ -1,106 +1,356 @@
 package inject
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
 
-var config configData = configData{}
+// ConfigVersion is the schema version written and understood by this package.
+// Files without a version header are read as version 1.
+const ConfigVersion = "1"
 
-type componentPath struct {
+var config Config = Config{}
+
+var strictConfig = true
+
+// SetStrictConfig enables or disables strict decoding of config files.
+// Strict decoding is on by default and rejects unknown or duplicated keys.
+func SetStrictConfig(strict bool) {
+	strictConfig = strict
+}
+
+// ComponentPath names a type in the config. Package is either the full import
+// path of the type's package (e.g. github.com/acme/billing/domain) or, when it
+// is unambiguous among the registered types, its short name (e.g. domain).
+type ComponentPath struct {
 	Name    string `yaml:"name"`
 	Package string `yaml:"package"`
 }
 
-func (path *componentPath) GetPath() string {
+func (path *ComponentPath) GetPath() string {
 	return path.Package + "." + path.Name
 }
 
-type factoryDescription struct {
-	componentPath `yaml:",inline"`
+// matches reports whether path names t, by full import path or short package name.
+func (path *ComponentPath) matches(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if path.Name != t.Name() {
+		return false
+	}
+	return path.Package == t.PkgPath() || path.Package == packageName(t)
+}
+
+// resolve returns the only candidate type named by path, nil when no candidate
+// matches, or an error when the short package name matches several packages.
+func (path *ComponentPath) resolve(candidates []reflect.Type) (reflect.Type, error) {
+	var found []reflect.Type
+	for _, t := range candidates {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if path.matches(t) && !containsType(found, t) {
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	}
+	keys := make([]string, len(found))
+	for i, t := range found {
+		keys[i] = typeKey(t)
+	}
+	sort.Strings(keys)
+	return nil, fmt.Errorf("%q is ambiguous, it matches %s; use the full import path as package", path.GetPath(), strings.Join(keys, ", "))
+}
+
+// FactoryDescription is an entry of the factories section.
+type FactoryDescription struct {
+	ComponentPath `yaml:",inline"`
 	IsSingleton   bool `yaml:"is-singleton"`
 }
 
-type injectableDescription struct {
-	componentPath `yaml:",inline"`
-	Factory       string `yaml:"factory"`
-	Params        any    `yaml:"params"`
-	InjectMode    string `yaml:"mode"` // values: auto, interface, factory
+// InjectableDescription is an entry of the injectables section.
+type InjectableDescription struct {
+	ComponentPath `yaml:",inline"`
+	Factory       string `yaml:"factory,omitempty"`
+	Params        any    `yaml:"params,omitempty"`
+	InjectMode    string `yaml:"mode,omitempty"` // values: auto, interface, factory
 }
 
-type interfaceDescription struct {
-	componentPath `yaml:",inline"`
-	Injectable    string `yaml:"injectable"`
+// InterfaceDescription is an entry of the interfaces section, binding an
+// interface to an injectable.
+type InterfaceDescription struct {
+	ComponentPath `yaml:",inline"`
+	Injectable    string   `yaml:"injectable"`
+	Named         string   `yaml:"named,omitempty"`      // matched by the name option of inject tags
+	Decorators    []string `yaml:"decorators,omitempty"` // names given to AddDecorator, applied in order
 }
 
-type configData struct {
-	Factories   []factoryDescription    `yaml:"factories"`
-	Injectables []injectableDescription `yaml:"injectables"`
-	Interfaces  []interfaceDescription  `yaml:"interfaces"`
+// Config is the yaml model of a config file.
+type Config struct {
+	Version     string                  `yaml:"version,omitempty"`
+	Factories   []FactoryDescription    `yaml:"factories,omitempty"`
+	Injectables []InjectableDescription `yaml:"injectables,omitempty"`
+	Interfaces  []InterfaceDescription  `yaml:"interfaces,omitempty"`
 }
 
-func getInjectable(fieldType string) *injectableDescription {
-	inter := config.getInterface(fieldType)
-	if inter == nil {
-		inj := config.getInjectable(fieldType)
-		if inj != nil && inj.InjectMode == "auto" {
-			return inj
-		}
-		return nil
-	}
-	if config.Injectables == nil {
-		return nil
+// getInjectable returns the injectable bound to t by the interfaces section,
+// or the injectable declared for t itself when its mode is auto. A non-empty
+// name selects the interfaces entry with the same named key.
+func getInjectable(t reflect.Type, name string) (*InjectableDescription, error) {
+	b, err := findBinding(t, name)
+	return b.injectable, err
+}
+
+// binding is the outcome of the config lookups for a type: the interfaces
+// entry mapping it, if any, and the injectable chosen.
+type binding struct {
+	inter      *InterfaceDescription
+	injectable *InjectableDescription
+	auto       bool // chosen by the mode auto entry of the type itself
+}
+
+// findBinding makes the decisions of getInjectable, keeping the interfaces
+// entry used and whether mode auto applied.
+func findBinding(t reflect.Type, name string) (binding, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return config.findBinding(t, name, registeredTypes(interfaces), registeredTypes(injectables))
+}
+
+// findBinding looks t up in the config, with the registered interfaces and
+// injectables its entries are resolved against.
+func (data *Config) findBinding(t reflect.Type, name string, interfaces, injectables []reflect.Type) (binding, error) {
+	var b binding
+	inter, err := data.getNamedInterface(t, name, interfaces)
+	if err != nil {
+		return b, err
 	}
-	for _, inj := range config.Injectables {
-		if inj.Name == inter.Injectable {
-			return &inj
+	b.inter = inter
+	if inter != nil {
+		inj, err := data.BoundInjectable(inter)
+		if inj != nil || err != nil {
+			b.injectable = inj
+			return b, err
 		}
 	}
-	inj := config.getInjectable(fieldType)
+	inj, err := data.getInjectable(t, injectables)
+	if err != nil {
+		return b, err
+	}
 	if inj != nil && inj.InjectMode == "auto" {
-		return inj
+		b.injectable, b.auto = inj, true
 	}
-	return nil
+	return b, nil
 }
 
-func (data *configData) getInterface(name string) *interfaceDescription {
-	if data.Interfaces == nil {
-		return nil
-	}
+func (data *Config) getInterface(t reflect.Type, interfaces []reflect.Type) (*InterfaceDescription, error) {
+	return data.getNamedInterface(t, "", interfaces)
+}
+
+// getNamedInterface returns the interfaces entry for t with the given named
+// key. Without a name, entries with no named key are preferred. Entries are
+// resolved against the registered interfaces.
+func (data *Config) getNamedInterface(t reflect.Type, name string, interfaces []reflect.Type) (*InterfaceDescription, error) {
+	candidates := append(interfaces, t)
+	var found *InterfaceDescription
 	for _, i := range data.Interfaces {
-		if i.Name == name || i.GetPath() == name {
-			return &i
+		if !i.matches(t) {
+			continue
+		}
+		if _, err := i.resolve(candidates); err != nil {
+			return nil, err
+		}
+		if i.Named == name {
+			return &i, nil
+		}
+		if name == "" && found == nil {
+			i := i
+			found = &i
 		}
 	}
-	return nil
+	if name != "" {
+		return nil, &unboundError{t: t, name: name}
+	}
+	return found, nil
 }
 
-func (data *configData) getInjectable(name string) *injectableDescription {
-	if data.Injectables == nil {
-		return nil
+// unboundError reports a type that the config doesn't bind to an injectable,
+// the only error skipped by the optional option of inject tags.
+type unboundError struct {
+	t    reflect.Type
+	name string
+}
+
+func (e *unboundError) Error() string {
+	if e.name != "" {
+		return fmt.Sprintf("interfaces: no entry for %v named %q", e.t, e.name)
 	}
+	return fmt.Sprintf("%v is not bound by the config", e.t)
+}
+
+// getInjectable returns the injectables entry for t, resolved against the
+// registered injectables.
+func (data *Config) getInjectable(t reflect.Type, injectables []reflect.Type) (*InjectableDescription, error) {
+	candidates := append(injectables, t)
 	for _, i := range data.Injectables {
-		if i.Name == name || i.GetPath() == name {
-			return &i
+		if !i.matches(t) {
+			continue
+		}
+		if _, err := i.resolve(candidates); err != nil {
+			return nil, err
 		}
+		return &i, nil
 	}
-	return nil
+	return nil, nil
 }
 
-func ImportConfig(filename string) {
-	resetFactories()
-	content, err := ioutil.ReadFile(filename)
+// BoundInjectable finds the injectables entry referenced by an interfaces
+// entry, either by name or by "package.Name" path. It returns nil when no
+// entry matches, and an error when the name matches entries of several
+// packages.
+func (data *Config) BoundInjectable(inter *InterfaceDescription) (*InjectableDescription, error) {
+	var found *InjectableDescription
+	for _, i := range data.Injectables {
+		if i.Name != inter.Injectable && i.GetPath() != inter.Injectable {
+			continue
+		}
+		if found != nil && found.Package != i.Package {
+			return nil, fmt.Errorf("interfaces: %q refers to injectable %q, which is declared in packages %q and %q; use package.Name", inter.GetPath(), inter.Injectable, found.Package, i.Package)
+		}
+		if found == nil {
+			i := i
+			found = &i
+		}
+	}
+	return found, nil
+}
+
+// ConfigError describes a problem found while reading a config file.
+type ConfigError struct {
+	File string
+	Line int
+	Key  string
+	Msg  string
+}
+
+func (e *ConfigError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s %q", location, e.Msg, e.Key)
+	}
+	return fmt.Sprintf("%s: %s", location, e.Msg)
+}
+
+var (
+	yamlUnknownField = regexp.MustCompile(`^line (\d+): field (\S+) not found in type \S+$`)
+	yamlDuplicateKey = regexp.MustCompile(`^line (\d+): key "?(.*?)"? already set in map$`)
+	yamlLine         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+)
+
+// newConfigError turns a single yaml.v2 message into a ConfigError.
+func newConfigError(filename string, message string) *ConfigError {
+	if m := yamlUnknownField.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &ConfigError{File: filename, Line: line, Key: m[2], Msg: "unknown key"}
+	}
+	if m := yamlDuplicateKey.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &ConfigError{File: filename, Line: line, Key: m[2], Msg: "duplicated key"}
+	}
+	if m := yamlLine.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &ConfigError{File: filename, Line: line, Msg: m[2]}
+	}
+	return &ConfigError{File: filename, Msg: message}
+}
+
+func parseConfig(filename string, content []byte) (Config, error) {
+	data := Config{}
+	var err error
+	if strictConfig {
+		err = yaml.UnmarshalStrict(content, &data)
+	} else {
+		err = yaml.Unmarshal(content, &data)
+	}
 
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		var errs ValidationErrors
+		for _, message := range typeError.Errors {
+			errs = append(errs, newConfigError(filename, message))
+		}
+		return data, errs
+	}
 	if err != nil {
-		log.Fatal(err)
+		return data, newConfigError(filename, err.Error())
 	}
 
-	err = yaml.Unmarshal(content, &config)
+	if data.Version != "" && data.Version != ConfigVersion {
+		return data, &ConfigError{File: filename, Key: data.Version, Msg: "unsupported config version"}
+	}
+	return data, nil
+}
 
+// ReadConfig reads and checks a yaml config file without applying it, for
+// tools working on config files.
+func ReadConfig(filename string) (*Config, error) {
+	content, err := ioutil.ReadFile(filename)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	data, err := parseConfig(filename, content)
+	if err != nil {
+		return nil, err
 	}
+	return &data, nil
+}
+
+// configFile is the name of the file loaded into config, reported by Explain.
+var configFile string
 
+// LoadConfig reads a yaml config file and replaces the whole current
+// configuration, without merging the sections the file omits.
+// On error the current configuration is left untouched.
+func LoadConfig(filename string) error {
+	data, err := ReadConfig(filename)
+	if err != nil {
+		return err
+	}
+
+	resetFactories()
+	stateMu.Lock()
+	config = *data
+	configFile = filename
+	stateMu.Unlock()
+	logger().Debug("inject: config loaded", slog.String("file", filename),
+		slog.Int("interfaces", len(data.Interfaces)),
+		slog.Int("injectables", len(data.Injectables)),
+		slog.Int("factories", len(data.Factories)))
+	return nil
+}
+
+// ImportConfig is like LoadConfig but terminates the program on error.
+func ImportConfig(filename string) {
+	if err := LoadConfig(filename); err != nil {
+		log.Fatal(err)
+	}
 }
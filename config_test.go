@@ -1,8 +1,15 @@
 package inject
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	billing "github.com/carlosranoya/inject/internal/testpkg/billing/domain"
+	users "github.com/carlosranoya/inject/internal/testpkg/users/domain"
 )
 
 // type iMessageTester interface {
@@ -130,10 +137,10 @@ func TestImportConfig(t *testing.T) {
 	t.Logf("\n\nimporting config file %s\n", file)
 	ImportConfig(file)
 
-	description := config.getInterface("inject.iMessagePrinter")
+	description, err := config.getInterface(reflect.TypeOf((*iMessagePrinter)(nil)).Elem(), registeredTypes(interfaces))
 	t.Log(description)
 
-	if description == nil {
+	if err != nil || description == nil {
 		t.Fatalf("Interface name. Expected not nil, got %v", description)
 	}
 
@@ -307,3 +314,425 @@ func TestInstanciateInjectable(t *testing.T) {
 	t.Logf(m)
 
 }
+
+func TestLoadConfigStrict(t *testing.T) {
+
+	init_instances()
+
+	file := "test_files/config_strict.yaml"
+	err := LoadConfig(file)
+
+	if err == nil {
+		t.Fatalf("LoadConfig(%s). Expected error, got nil", file)
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("LoadConfig(%s). Expected 2 errors, got %v", file, err)
+	}
+
+	expected := []ConfigError{
+		{File: file, Line: 6, Key: "is_singleton", Msg: "unknown key"},
+		{File: file, Line: 10, Key: "injectible", Msg: "unknown key"},
+	}
+	for i, e := range expected {
+		var configError *ConfigError
+		if !errors.As(errs[i], &configError) || *configError != e {
+			t.Fatalf("LoadConfig(%s). Expected %v, got %v", file, &e, errs[i])
+		}
+	}
+
+	SetStrictConfig(false)
+	defer SetStrictConfig(true)
+
+	if err := LoadConfig(file); err != nil {
+		t.Fatalf("LoadConfig(%s) with strict mode off. Expected nil, got %v", file, err)
+	}
+
+}
+
+func TestLoadConfigVersion(t *testing.T) {
+
+	init_instances()
+
+	ImportConfig("test_files/injection-config.qa.yaml")
+
+	file := "test_files/config_version.yaml"
+	err := LoadConfig(file)
+
+	var configError *ConfigError
+	if !errors.As(err, &configError) || configError.Key != "2" {
+		t.Fatalf("LoadConfig(%s). Expected unsupported version error, got %v", file, err)
+	}
+
+	description, _ := config.getInterface(reflect.TypeOf((*iMessagePrinter)(nil)).Elem(), registeredTypes(interfaces))
+	if description == nil || description.Injectable != "messagePrinterC" {
+		t.Fatalf("LoadConfig(%s). Expected previous config to be kept, got %v", file, description)
+	}
+
+}
+
+func TestLoadConfigReplaces(t *testing.T) {
+
+	init_instances()
+
+	ImportConfig("test_files/injection-config.local.yaml")
+
+	file := "test_files/config_keys.yaml"
+	if err := LoadConfig(file); err != nil {
+		t.Fatalf("LoadConfig(%s). Expected no error, got %v", file, err)
+	}
+	if len(config.Factories) != 0 || len(config.Interfaces) != 0 {
+		t.Fatalf("LoadConfig(%s). Expected the sections it omits to be empty, got %+v", file, config)
+	}
+}
+
+func initDomainInstances() {
+	ResetData()
+	AddInterface[billing.Repository]()
+	AddInterface[users.Repository]()
+	AddInjectable[billing.SQLRepository]()
+	AddInjectable[users.SQLRepository]()
+}
+
+func TestConfigWithImportPaths(t *testing.T) {
+
+	initDomainInstances()
+
+	ImportConfig("test_files/config_import_path.yaml")
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+
+	type Container struct {
+		Billing billing.Repository `inject:"struct"`
+		Users   users.Repository   `inject:"struct"`
+	}
+	container := Container{}
+	if err := Inject(&container); err != nil {
+		t.Fatalf("Inject(). Unexpected error: %v", err)
+	}
+
+	if container.Billing == nil || container.Billing.Owner() != "billing:invoices" {
+		t.Fatalf("Inject(). Expected billing:invoices, got %v", container.Billing)
+	}
+	if container.Users == nil || container.Users.Owner() != "users:accounts" {
+		t.Fatalf("Inject(). Expected users:accounts, got %v", container.Users)
+	}
+
+}
+
+func TestConfigWithAmbiguousPackage(t *testing.T) {
+
+	initDomainInstances()
+
+	ImportConfig("test_files/config_ambiguous.yaml")
+
+	_, err := InstaciateInjected[billing.Repository]()
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("InstaciateInjected(). Expected ambiguity error, got %v", err)
+	}
+
+	if err := Validate(); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("Validate(). Expected ambiguity error, got %v", err)
+	}
+
+	// a short name is fine as long as only one package uses it
+	ResetData()
+	AddInterface[billing.Repository]()
+	AddInjectable[billing.SQLRepository]()
+
+	r, err := InstaciateInjected[billing.Repository]()
+	if err != nil || r.Owner() != "billing:" {
+		t.Fatalf("InstaciateInjected(). Expected billing repository, got %v, %v", r, err)
+	}
+
+}
+
+type envSettings struct {
+	Host string `env:"INJECT_TEST_SETTINGS_HOST"`
+	Port int    `env:"INJECT_TEST_SETTINGS_PORT"`
+	User string `inject:"user" value:"tag-user"`
+}
+
+func TestConfigParamsWithEnv(t *testing.T) {
+
+	ResetData()
+	AddInjectable[envSettings]()
+
+	ImportConfig("test_files/config_env.yaml")
+
+	t.Setenv("INJECT_TEST_SETTINGS_HOST", "env-host")
+
+	settings, err := InstaciateInjected[*envSettings]()
+
+	if err != nil {
+		t.Fatalf("Error calling InstaciateInjected: %v", err)
+	}
+
+	// env > config params > value tag
+	expected := envSettings{Host: "env-host", Port: 8000, User: "config-user"}
+	if *settings != expected {
+		t.Fatalf("InstaciateInjected(). Expected %+v, got %+v", expected, *settings)
+	}
+
+	type Container struct {
+		Settings *envSettings `inject:"struct"`
+	}
+
+	container, err := Instanciate[Container]()
+
+	if err != nil {
+		t.Fatalf("Error calling Instanciate: %v", err)
+	}
+
+	if *container.Settings != expected {
+		t.Fatalf("Instanciate(). Expected %+v, got %+v", expected, *container.Settings)
+	}
+
+}
+
+type layeredSettings struct {
+	A string `inject:"a" value:"tag"`
+	B string `inject:"b" value:"tag"`
+	C string `inject:"c" value:"tag" env:"INJECT_TEST_LAYER_C"`
+	D string `inject:"d" value:"tag" env:"INJECT_TEST_LAYER_D"`
+	E string
+}
+
+func TestSourcePrecedence(t *testing.T) {
+
+	ResetData()
+	AddInjectable[layeredSettings]()
+
+	ImportConfig("test_files/config_layers.yaml")
+
+	t.Setenv("INJECT_TEST_LAYER_C", "env")
+	t.Setenv("INJECT_TEST_LAYER_D", "env")
+
+	type Container struct {
+		Settings *layeredSettings `inject:"struct"`
+		D        string           `inject:"d" value:"tag" env:"INJECT_TEST_LAYER_D"`
+		F        string           `inject:"f" value:"tag"`
+	}
+
+	container, sources, err := InstanciateWithSources[Container](Args{"D": "args"}, false)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithSources: %v", err)
+	}
+
+	expected := layeredSettings{A: "tag", B: "params", C: "env", D: "env", E: "params"}
+	if *container.Settings != expected {
+		t.Fatalf("InstanciateWithSources(). Expected %+v, got %+v", expected, *container.Settings)
+	}
+	if container.D != "args" || container.F != "tag" {
+		t.Fatalf("InstanciateWithSources(). Expected D = args and F = tag, got %+v", container)
+	}
+
+	expectedSources := FieldSources{
+		"Settings":   SourceBinding,
+		"Settings.A": SourceTag,
+		"Settings.B": SourceParams,
+		"Settings.C": SourceEnv,
+		"Settings.D": SourceEnv,
+		"Settings.E": SourceParams,
+		"D":          SourceArgs,
+		"F":          SourceTag,
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Fatalf("InstanciateWithSources(). Expected sources %v, got %v", expectedSources, sources)
+	}
+
+	// the same layers apply when the injectable is instanciated directly
+	settings, err := InstaciateInjected[*layeredSettings]()
+
+	if err != nil {
+		t.Fatalf("Error calling InstaciateInjected: %v", err)
+	}
+	if *settings != expected {
+		t.Fatalf("InstaciateInjected(). Expected %+v, got %+v", expected, *settings)
+	}
+
+}
+
+type poolSettings struct {
+	Size int           `inject:"size" value:"4"`
+	Idle time.Duration `inject:"idle" value:"30s"`
+}
+
+type dbSettings struct {
+	Host     string       `inject:"host" value:"localhost"`
+	Port     uint16       `inject:"port" value:"5432"`
+	Pool     poolSettings `inject:"pool"`
+	Password string       `env:"INJECT_TEST_DB_PASSWORD"`
+}
+
+type nestedService struct {
+	DB      dbSettings `inject:"db"`
+	Replica *dbSettings
+	Backups []dbSettings
+	Shards  map[string]*dbSettings
+	Limits  map[string]uint8
+}
+
+func TestNestedConfigParams(t *testing.T) {
+
+	ResetData()
+	AddInjectable[nestedService]()
+
+	ImportConfig("test_files/config_nested.yaml")
+
+	t.Setenv("INJECT_TEST_DB_PASSWORD", "secret")
+
+	type Container struct {
+		Service *nestedService `inject:"struct"`
+	}
+
+	container, sources, err := InstanciateWithSources[Container](nil, false)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithSources: %v", err)
+	}
+
+	pool := poolSettings{Size: 4, Idle: 30 * time.Second}
+	expected := nestedService{
+		DB:      dbSettings{Host: "db.internal", Port: 5432, Pool: poolSettings{Size: 10, Idle: 30 * time.Second}, Password: "secret"},
+		Replica: &dbSettings{Host: "localhost", Port: 6543, Pool: pool, Password: "secret"},
+		Backups: []dbSettings{
+			{Host: "b1", Port: 5432, Pool: pool, Password: "secret"},
+			{Host: "b2", Port: 7000, Pool: pool, Password: "secret"},
+		},
+		Shards: map[string]*dbSettings{"eu": {Host: "eu.db", Port: 5432, Pool: pool, Password: "secret"}},
+		Limits: map[string]uint8{"a": 1, "b": 2},
+	}
+
+	if !reflect.DeepEqual(*container.Service, expected) {
+		t.Fatalf("InstanciateWithSources().\nExpected %+v\ngot      %+v", expected, *container.Service)
+	}
+
+	expectedSources := map[string]Source{
+		"Service.DB.Host":      SourceParams,
+		"Service.DB.Port":      SourceTag,
+		"Service.DB.Pool.Size": SourceParams,
+		"Service.DB.Pool.Idle": SourceTag,
+		"Service.DB.Password":  SourceEnv,
+		"Service.Replica.Port": SourceParams,
+		"Service.Backups":      SourceParams,
+	}
+	for path, source := range expectedSources {
+		if sources[path] != source {
+			t.Fatalf("InstanciateWithSources(). Expected source of %s = %v, got %v", path, source, sources[path])
+		}
+	}
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+
+}
+
+type keyedSettings struct {
+	Address  string `inject:"listen"`
+	LogLevel string `yaml:"log_level"`
+	MaxConns int    `json:"maxConns,omitempty"`
+	Timeout  time.Duration
+	Retries  int
+}
+
+func TestConfigParamsKeys(t *testing.T) {
+
+	ResetData()
+	AddInjectable[keyedSettings]()
+
+	ImportConfig("test_files/config_keys.yaml")
+
+	settings, err := InstaciateInjected[*keyedSettings]()
+
+	if err != nil {
+		t.Fatalf("Error calling InstaciateInjected: %v", err)
+	}
+
+	expected := keyedSettings{Address: ":8080", LogLevel: "debug", MaxConns: 100, Timeout: 5 * time.Second, Retries: 3}
+	if *settings != expected {
+		t.Fatalf("InstaciateInjected(). Expected %+v, got %+v", expected, *settings)
+	}
+
+	ImportConfig("test_files/config_unknown_keys.yaml")
+
+	_, err = InstaciateInjected[*keyedSettings]()
+
+	if err == nil || !strings.Contains(err.Error(), "lisen, verbose") {
+		t.Fatalf("InstaciateInjected() with unknown params keys. Expected error naming lisen and verbose, got %v", err)
+	}
+
+	if err := Validate(); err == nil {
+		t.Fatalf("Validate() with unknown params keys. Expected error, got nil")
+	}
+
+}
+
+type privatePrinter struct {
+	message string `inject:"message,private" value:"private default"`
+}
+
+func (p *privatePrinter) Print() {
+	fmt.Println(p.GetMessage())
+}
+func (p *privatePrinter) GetMessage() string {
+	return p.message
+}
+
+func TestPrivateFields(t *testing.T) {
+
+	ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[privatePrinter]()
+
+	ImportConfig("test_files/config_private.yaml")
+
+	type inner struct {
+		value int `inject:"value,private" value:"1"`
+	}
+
+	type holder struct {
+		message string           `inject:"message,private" value:"hello"`
+		count   int              `inject:"count,private"`
+		printer iMessagePrinter  `inject:"printer,struct,private"`
+		sub     *messagePrinterB `inject:"sub,private" value:"{\"Message\": \"nested\"}"`
+		inner   inner            `inject:"inner,private"`
+		list    []string         `inject:"list,private" env:"INJECT_TEST_PRIVATE_LIST"`
+	}
+
+	t.Setenv("INJECT_TEST_PRIVATE_LIST", `["a", "b"]`)
+
+	h, err := InstanciateWithArgs[holder](Args{"count": 3, "inner": map[string]any{"value": 2}}, true)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithArgs: %v", err)
+	}
+
+	if h.message != "hello" || h.count != 3 || h.inner.value != 2 || !reflect.DeepEqual(h.list, []string{"a", "b"}) {
+		t.Fatalf("InstanciateWithArgs(). Private fields not injected: %+v", h)
+	}
+	if h.sub == nil || h.sub.Message != "nested" {
+		t.Fatalf("InstanciateWithArgs(). Expected private pointer with nested message, got %v", h.sub)
+	}
+	if h.printer == nil || h.printer.GetMessage() != "private message from config" {
+		t.Fatalf("InstanciateWithArgs(). Expected private interface bound by config, got %v", h.printer)
+	}
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+
+	type notPrivate struct {
+		message string `inject:"message" value:"hello"`
+	}
+
+	if _, err := Instanciate[notPrivate](); err == nil || !strings.Contains(err.Error(), "private") {
+		t.Fatalf("Instanciate() with unexported field. Expected error about the private option, got %v", err)
+	}
+
+}
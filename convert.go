@@ -0,0 +1,417 @@
+package inject
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/carlosranoya/inject/internal/tags"
+)
+
+type converter func(text string) (reflect.Value, error)
+
+var converters = map[reflect.Type]converter{}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+func init() {
+	RegisterConverter(time.ParseDuration)
+	RegisterConverter(url.Parse)
+	RegisterConverter(func(text string) (url.URL, error) {
+		u, err := url.Parse(text)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *u, nil
+	})
+}
+
+// RegisterConverter registers a function that turns the text of value tags
+// and string params into a T. Converters take precedence over
+// encoding.TextUnmarshaler and json.Unmarshaler implementations.
+func RegisterConverter[T any](convert func(string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	converters[t] = func(text string) (reflect.Value, error) {
+		v, err := convert(text)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+}
+
+// hasDecoder reports whether text values for t are decoded by a registered
+// converter, encoding.TextUnmarshaler or json.Unmarshaler instead of by kind.
+func hasDecoder(t reflect.Type) bool {
+	stateMu.Lock()
+	_, ok := converters[t]
+	stateMu.Unlock()
+	if ok {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	p := reflect.PointerTo(t)
+	return p.Implements(textUnmarshalerType) || p.Implements(jsonUnmarshalerType)
+}
+
+// decodeText decodes text into t using a custom decoder. It returns false
+// when t has none.
+func decodeText(text string, t reflect.Type) (reflect.Value, bool, error) {
+	stateMu.Lock()
+	convert, ok := converters[t]
+	stateMu.Unlock()
+	if ok {
+		v, err := convert(text)
+		if err != nil {
+			return v, true, fmt.Errorf("cannot parse %q as %v: %w", text, t, err)
+		}
+		return v, true, nil
+	}
+	if !hasDecoder(t) {
+		return reflect.Value{}, false, nil
+	}
+
+	elem := t
+	if t.Kind() == reflect.Pointer {
+		elem = t.Elem()
+	}
+	p := reflect.New(elem)
+
+	var err error
+	if u, ok := p.Interface().(encoding.TextUnmarshaler); ok {
+		err = u.UnmarshalText([]byte(text))
+	} else {
+		data := []byte(text)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(text)
+		}
+		err = p.Interface().(json.Unmarshaler).UnmarshalJSON(data)
+	}
+	if err != nil {
+		return reflect.Value{}, true, fmt.Errorf("cannot parse %q as %v: %w", text, t, err)
+	}
+	if t.Kind() == reflect.Pointer {
+		return p, true, nil
+	}
+	return p.Elem(), true, nil
+}
+
+// convertValue converts a value coming from Args, positional args or config
+// params to type t. Numbers are converted between any numeric kinds as long
+// as the value fits in the target type; strings are parsed like value tags.
+func (in *injection) convertValue(value any, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+	if text, ok := value.(string); ok {
+		if v, ok, err := decodeText(text, t); ok {
+			return v, err
+		}
+	}
+	v := reflect.ValueOf(value)
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if v.Kind() == reflect.String {
+			return parseValue(v.String(), t)
+		}
+		return convertNumber(v, t)
+
+	case reflect.Bool:
+		switch {
+		case v.Kind() == reflect.Bool:
+			return reflect.ValueOf(v.Bool()).Convert(t), nil
+		case v.Kind() == reflect.String:
+			return parseValue(v.String(), t)
+		case isNumberKind(v.Kind()):
+			f, _ := numberAsFloat(v)
+			return reflect.ValueOf(f != 0).Convert(t), nil
+		}
+
+	case reflect.String:
+		if v.Kind() == reflect.String {
+			return v.Convert(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use %T as %v", value, t)
+	}
+
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			return in.convertList(v, t)
+		}
+	case reflect.Map:
+		if v.Kind() == reflect.Map {
+			return in.convertMap(v, t)
+		}
+	case reflect.Struct:
+		if v.Kind() == reflect.Map {
+			return in.convertStruct(v, t)
+		}
+	case reflect.Pointer:
+		elem, err := in.convertValue(value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(elem)
+		return p, nil
+	}
+
+	if v.Kind() == t.Kind() && v.Type().ConvertibleTo(t) {
+		return v.Convert(t), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %T as %v", value, t)
+}
+
+// parseValue parses the text of a value tag into type t.
+func parseValue(text string, t reflect.Type) (reflect.Value, error) {
+	if v, ok, err := decodeText(text, t); ok {
+		return v, err
+	}
+	value := strings.TrimSpace(text)
+
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(text).Convert(t), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, numberError(text, t, err)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(value, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, numberError(text, t, err)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return reflect.Value{}, numberError(text, t, err)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+
+	case reflect.Bool:
+		value = strings.ToLower(value)
+		b := !(value == "false" || value == "0" || value == "nil" || value == "none" || value == "null" || value == "")
+		return reflect.ValueOf(b).Convert(t), nil
+
+	case reflect.Interface:
+		if reflect.TypeOf(text).AssignableTo(t) {
+			return reflect.ValueOf(text), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot parse %q as %v", text, t)
+}
+
+func numberError(text string, t reflect.Type, err error) error {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return fmt.Errorf("value %s overflows %v", text, t)
+	}
+	return fmt.Errorf("cannot parse %q as %v", text, t)
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func numberAsFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int()), true
+	case isUintKind(v.Kind()):
+		return float64(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// convertNumber converts between numeric kinds, failing instead of silently
+// wrapping around when the value doesn't fit in t.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	target := reflect.New(t).Elem()
+	overflow := fmt.Errorf("value %v overflows %v", v.Interface(), t)
+
+	switch k := v.Kind(); {
+	case isIntKind(k):
+		n := v.Int()
+		switch {
+		case isIntKind(t.Kind()):
+			if target.OverflowInt(n) {
+				return reflect.Value{}, overflow
+			}
+			target.SetInt(n)
+		case isUintKind(t.Kind()):
+			if n < 0 || target.OverflowUint(uint64(n)) {
+				return reflect.Value{}, overflow
+			}
+			target.SetUint(uint64(n))
+		default:
+			target.SetFloat(float64(n))
+		}
+
+	case isUintKind(k):
+		n := v.Uint()
+		switch {
+		case isIntKind(t.Kind()):
+			if n > math.MaxInt64 || target.OverflowInt(int64(n)) {
+				return reflect.Value{}, overflow
+			}
+			target.SetInt(int64(n))
+		case isUintKind(t.Kind()):
+			if target.OverflowUint(n) {
+				return reflect.Value{}, overflow
+			}
+			target.SetUint(n)
+		default:
+			target.SetFloat(float64(n))
+		}
+
+	case k == reflect.Float32 || k == reflect.Float64:
+		f := v.Float()
+		switch {
+		case isIntKind(t.Kind()), isUintKind(t.Kind()):
+			if f != math.Trunc(f) || math.IsInf(f, 0) {
+				return reflect.Value{}, fmt.Errorf("value %v is not an integer, can't use it as %v", f, t)
+			}
+			if isIntKind(t.Kind()) {
+				if f < math.MinInt64 || f >= math.MaxInt64 || target.OverflowInt(int64(f)) {
+					return reflect.Value{}, overflow
+				}
+				target.SetInt(int64(f))
+			} else {
+				if f < 0 || f >= math.MaxUint64 || target.OverflowUint(uint64(f)) {
+					return reflect.Value{}, overflow
+				}
+				target.SetUint(uint64(f))
+			}
+		default:
+			if target.OverflowFloat(f) {
+				return reflect.Value{}, overflow
+			}
+			target.SetFloat(f)
+		}
+
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use %v as %v", v.Type(), t)
+	}
+
+	return target, nil
+}
+
+// convertList converts each element of a slice or array into the element
+// type of t. Arrays may receive fewer elements than their length.
+func (in *injection) convertList(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	var list reflect.Value
+	if t.Kind() == reflect.Array {
+		if v.Len() > t.Len() {
+			return reflect.Value{}, fmt.Errorf("%d values don't fit in %v", v.Len(), t)
+		}
+		list = reflect.New(t).Elem()
+	} else {
+		list = reflect.MakeSlice(t, v.Len(), v.Len())
+	}
+	for i := 0; i < v.Len(); i++ {
+		elem, err := in.convertValue(v.Index(i).Interface(), t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("[%d]: %w", i, err)
+		}
+		list.Index(i).Set(elem)
+	}
+	return list, nil
+}
+
+// convertMap converts each key and value of a map into the key and element
+// types of t. String keys are parsed, so JSON objects can fill map[int]T.
+func (in *injection) convertMap(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	m := reflect.MakeMapWithSize(t, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := in.convertValue(iter.Key().Interface(), t.Key())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %v: %w", iter.Key().Interface(), err)
+		}
+		elem, err := in.convertValue(iter.Value().Interface(), t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("[%v]: %w", iter.Key().Interface(), err)
+		}
+		m.SetMapIndex(key, elem)
+	}
+	return m, nil
+}
+
+// convertStruct fills a new struct of type t from a map, matching keys to
+// field names or inject tag aliases. Fields missing from the map keep the
+// defaults of their value tags, injected as a dry run when in is one, and
+// not at all when in only checks conversions.
+func (in *injection) convertStruct(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	p := reflect.New(t)
+	if !in.checkOnly {
+		if err := (&injection{dryRun: in.dryRun}).inject(p); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	s := p.Elem()
+	iter := v.MapRange()
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+		index, ok := fieldIndexByKey(t, key)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%v has no field %q", t, key)
+		}
+		field := s.Field(index)
+		if !field.CanSet() {
+			if tag, _ := parseInjectTag(t.Field(index)); !tag.Private {
+				return reflect.Value{}, fmt.Errorf("field %s of %v is not settable", t.Field(index).Name, t)
+			}
+			field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+		}
+		elem, err := in.convertValue(iter.Value().Interface(), field.Type())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", key, err)
+		}
+		field.Set(elem)
+	}
+	return s, nil
+}
+
+// fieldIndexByKey finds the field of t named by a params key, see
+// tags.FieldIndexByKey.
+func fieldIndexByKey(t reflect.Type, key string) (int, bool) {
+	return tags.FieldIndexByKey(tagFields(t), key)
+}
@@ -0,0 +1,203 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvertValue(t *testing.T) {
+
+	type level uint8
+
+	tests := []struct {
+		value    any
+		target   any
+		expected any
+	}{
+		{12, int8(0), int8(12)},
+		{12, uint16(0), uint16(12)},
+		{12.0, uint32(0), uint32(12)},
+		{int64(-3), int16(0), int16(-3)},
+		{uint64(7), int(0), 7},
+		{3, float32(0), float32(3)},
+		{1.5, float32(0), float32(1.5)},
+		{"42", uint64(0), uint64(42)},
+		{" -42 ", int32(0), int32(-42)},
+		{"2.5", float64(0), 2.5},
+		{2, level(0), level(2)},
+		{1.0, false, true},
+		{"false", true, false},
+		{0, true, false},
+		{"text", "", "text"},
+		{nil, 0, 0},
+	}
+
+	for _, test := range tests {
+		v, err := (&injection{}).convertValue(test.value, reflect.TypeOf(test.target))
+		if err != nil {
+			t.Fatalf("convertValue(%#v, %T). Unexpected error: %v", test.value, test.target, err)
+		}
+		if v.Interface() != test.expected {
+			t.Fatalf("convertValue(%#v, %T) = %#v, expected %#v", test.value, test.target, v.Interface(), test.expected)
+		}
+	}
+
+}
+
+func TestConvertValueErrors(t *testing.T) {
+
+	tests := []struct {
+		value  any
+		target any
+	}{
+		{300, int8(0)},
+		{-1, uint(0)},
+		{256.0, uint8(0)},
+		{1.5, 0},
+		{math.Inf(1), int64(0)},
+		{uint64(math.MaxUint64), int64(0)},
+		{1e300, float32(0)},
+		{"70000", uint16(0)},
+		{"abc", 0},
+		{12, ""},
+		{"text", []int{}},
+	}
+
+	for _, test := range tests {
+		if v, err := (&injection{}).convertValue(test.value, reflect.TypeOf(test.target)); err == nil {
+			t.Fatalf("convertValue(%#v, %T) = %#v, expected error", test.value, test.target, v.Interface())
+		}
+	}
+
+}
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+)
+
+func parseLogLevel(text string) (logLevel, error) {
+	switch text {
+	case "debug":
+		return levelDebug, nil
+	case "info":
+		return levelInfo, nil
+	case "warn":
+		return levelWarn, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", text)
+}
+
+type color struct {
+	Name string
+}
+
+func (c *color) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty color")
+	}
+	c.Name = strings.ToUpper(string(text))
+	return nil
+}
+
+type serverConfig struct {
+	Timeout  time.Duration  `inject:"timeout" value:"5s"`
+	Endpoint url.URL        `inject:"endpoint" value:"http://localhost:8080/path"`
+	Proxy    *url.URL       `inject:"proxy" value:"http://proxy:3128"`
+	Address  net.IP         `inject:"address" value:"127.0.0.1"`
+	Pattern  *regexp.Regexp `inject:"pattern" value:"^[0-9]+$"`
+	Level    logLevel       `inject:"level" value:"info"`
+	Color    color          `inject:"color" value:"red"`
+}
+
+func TestInstanciateWithDecoders(t *testing.T) {
+
+	RegisterConverter(parseLogLevel)
+
+	s, err := Instanciate[serverConfig]()
+
+	if err != nil {
+		t.Fatalf("Error calling Instanciate: %v", err)
+	}
+
+	if s.Timeout != 5*time.Second {
+		t.Fatalf("Timeout = %v, expected %v", s.Timeout, 5*time.Second)
+	}
+	if s.Endpoint.Host != "localhost:8080" || s.Endpoint.Path != "/path" {
+		t.Fatalf("Endpoint = %v, expected http://localhost:8080/path", s.Endpoint.String())
+	}
+	if s.Proxy == nil || s.Proxy.Host != "proxy:3128" {
+		t.Fatalf("Proxy = %v, expected http://proxy:3128", s.Proxy)
+	}
+	if !s.Address.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("Address = %v, expected 127.0.0.1", s.Address)
+	}
+	if s.Pattern == nil || !s.Pattern.MatchString("123") || s.Pattern.MatchString("abc") {
+		t.Fatalf("Pattern = %v, expected ^[0-9]+$", s.Pattern)
+	}
+	if s.Level != levelInfo {
+		t.Fatalf("Level = %v, expected %v", s.Level, levelInfo)
+	}
+	if s.Color.Name != "RED" {
+		t.Fatalf("Color = %v, expected RED", s.Color.Name)
+	}
+
+	type invalidStruct struct {
+		Level logLevel `inject:"level" value:"verbose"`
+	}
+
+	if _, err := Instanciate[invalidStruct](); err == nil {
+		t.Fatalf("Instanciate with invalid converter input. Expected error, got nil")
+	}
+
+}
+
+func TestConfigParamsWithDecoders(t *testing.T) {
+
+	ResetData()
+	RegisterConverter(parseLogLevel)
+	AddInjectable[serverConfig]()
+
+	ImportConfig("test_files/config_decoders.yaml")
+
+	s, err := InstaciateInjected[*serverConfig]()
+
+	if err != nil {
+		t.Fatalf("Error calling InstaciateInjected: %v", err)
+	}
+
+	if s.Timeout != 90*time.Second {
+		t.Fatalf("Timeout = %v, expected %v", s.Timeout, 90*time.Second)
+	}
+	if s.Endpoint.String() != "https://example.com/api" {
+		t.Fatalf("Endpoint = %v, expected https://example.com/api", s.Endpoint.String())
+	}
+	if !s.Address.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fatalf("Address = %v, expected 10.0.0.1", s.Address)
+	}
+	if s.Pattern == nil || !s.Pattern.MatchString("abc") {
+		t.Fatalf("Pattern = %v, expected ^[a-z]+$", s.Pattern)
+	}
+	if s.Level != levelWarn {
+		t.Fatalf("Level = %v, expected %v", s.Level, levelWarn)
+	}
+	if s.Color.Name != "BLUE" {
+		t.Fatalf("Color = %v, expected BLUE", s.Color.Name)
+	}
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+
+}
@@ -1,8 +1,9 @@
 package inject
 
 import (
-	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 )
 
 type interfaceWrapper[T any] struct {
@@ -16,6 +17,15 @@ type injectedFactory[T any] struct {
 
 type iResetable interface {
 	Reset()
+	clone() iResetable
+}
+
+type iLifetime interface {
+	singleton() bool
+}
+
+type iInstanceFactory interface {
+	instanceValue() reflect.Value
 }
 
 func (factory *injectedFactory[T]) GetInstance() *T {
@@ -40,15 +50,46 @@ func (factory *injectedFactory[T]) getInstanceWithArgs(args Args) *T {
 func (factory *injectedFactory[T]) Reset() {
 	factory.instance = nil
 }
+func (factory *injectedFactory[T]) clone() iResetable {
+	clone := *factory
+	return &clone
+}
+func (factory *injectedFactory[T]) singleton() bool {
+	return factory.IsSingleton
+}
+func (factory *injectedFactory[T]) instanceValue() reflect.Value {
+	instance := factory.GetInstance()
+	if instance == nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(instance)
+}
 
 func ResetData() {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
 	factories = make(map[reflect.Type]iResetable)
 
 	interfaces = make(map[string]reflect.Type)
 
 	injectables = make(map[string]reflect.Type)
+
+	injectionMethods = make(map[reflect.Type][]string)
+
+	singletons = make(map[scopeKey]reflect.Value)
+
+	decorators = make(map[reflect.Type][]decorator)
+
+	namedDecorators = make(map[reflect.Type]map[string]decorator)
 }
 
+// stateMu guards the state shared by concurrent resolutions: the tag plans
+// and singleton instances cached while resolving, the overrides, and the
+// registry and config against registrations, LoadConfig, ResetData and
+// Restore. It's never held while user code, like decorators, runs.
+var stateMu sync.Mutex
+
 var factories map[reflect.Type]iResetable = make(map[reflect.Type]iResetable)
 
 var interfaces map[string]reflect.Type = make(map[string]reflect.Type)
@@ -56,16 +97,22 @@ var interfaces map[string]reflect.Type = make(map[string]reflect.Type)
 var injectables map[string]reflect.Type = make(map[string]reflect.Type)
 
 func resetFactories() {
+	stateMu.Lock()
+	defer stateMu.Unlock()
 	for _, v := range factories {
 		v.Reset()
 	}
+	singletons = make(map[scopeKey]reflect.Value)
 }
 
 func AddFactory[T any](obj *T, IsSingleton bool) error {
 	v := reflect.ValueOf(obj).Elem()
 	t := v.Type()
 	factory := injectedFactory[T]{IsSingleton: IsSingleton}
+	stateMu.Lock()
 	factories[t] = &factory
+	stateMu.Unlock()
+	logRegistration("factory", t)
 	return nil
 }
 
@@ -77,6 +124,7 @@ func checkType[T any](Type reflect.Type) bool {
 
 func GetInstance[T any](args Args) *T {
 	var f iResetable
+	stateMu.Lock()
 	for k, v := range factories {
 		ok := checkType[T](k)
 		if ok {
@@ -84,6 +132,7 @@ func GetInstance[T any](args Args) *T {
 			break
 		}
 	}
+	stateMu.Unlock()
 	if f == nil {
 		return nil
 	}
@@ -100,14 +149,18 @@ func AddInterface[T any]() {
 
 func AddInterfacePointer(pointer any) {
 	t := reflect.TypeOf(pointer).Elem()
-	name := fmt.Sprintf("%v", reflect.TypeOf(pointer).Elem())
-	interfaces[name] = t
+	stateMu.Lock()
+	interfaces[typeKey(t)] = t
+	stateMu.Unlock()
+	logRegistration("interface", t)
 }
 
 func addWrappedInterface[T any](wrapper interfaceWrapper[T]) {
 	t := reflect.TypeOf(wrapper.pointer).Elem()
-	name := fmt.Sprintf("%v", reflect.TypeOf(wrapper.pointer).Elem())
-	interfaces[name] = t
+	stateMu.Lock()
+	interfaces[typeKey(t)] = t
+	stateMu.Unlock()
+	logRegistration("interface", t)
 }
 
 func AddInjectable[T any]() {
@@ -117,10 +170,70 @@ func AddInjectable[T any]() {
 
 func addInjectable(obj any) {
 	t := reflect.TypeOf(obj)
-	name := fmt.Sprintf("%v", t)
-	injectables[name] = t
+	stateMu.Lock()
+	injectables[typeKey(t)] = t
+	stateMu.Unlock()
+	logRegistration("injectable", t)
+}
+
+func getInjectableType(path ComponentPath) (reflect.Type, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return path.resolve(registeredTypes(injectables))
+}
+
+func getInterfaceType(path ComponentPath) (reflect.Type, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return path.resolve(registeredTypes(interfaces))
+}
+
+func getFactoryType(path ComponentPath) (reflect.Type, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return path.resolve(factoryTypes(factories))
+}
+
+// typeKey identifies a type by the full import path of its package and its
+// name, so that types from different packages with the same short name
+// don't collide.
+func typeKey(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// packageName returns the short package name of a named type, as it appears
+// in t.String().
+func packageName(t reflect.Type) string {
+	return strings.TrimSuffix(t.String(), "."+t.Name())
 }
 
-func getInjectableType(name string) reflect.Type {
-	return injectables[name]
+func registeredTypes(registry map[string]reflect.Type) []reflect.Type {
+	types := make([]reflect.Type, 0, len(registry))
+	for _, t := range registry {
+		types = append(types, t)
+	}
+	return types
+}
+
+func factoryTypes(registry map[reflect.Type]iResetable) []reflect.Type {
+	types := make([]reflect.Type, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	return types
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
 }
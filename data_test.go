@@ -86,9 +86,9 @@ func TestAddAndGetInjectables(t *testing.T) {
 		t.Logf("key: %v, value:%v", a, b)
 	}
 
-	T := getInjectableType("inject.Injectable")
+	T, err := getInjectableType(ComponentPath{Name: "Injectable", Package: "inject"})
 
-	if T != reflect.TypeOf(I) {
+	if err != nil || T != reflect.TypeOf(I) {
 		t.Fatalf("wrong type of struct %v, got %v", delta, T)
 	}
 
@@ -0,0 +1,98 @@
+package inject
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// decorator wraps a resolved instance, given and returned as a value of the
+// decorated type. It fails when the instance is not of that type.
+type decorator func(reflect.Value) (reflect.Value, error)
+
+var decorators map[reflect.Type][]decorator = make(map[reflect.Type][]decorator)
+
+var namedDecorators map[reflect.Type]map[string]decorator = make(map[reflect.Type]map[string]decorator)
+
+// Decorate registers a function wrapping every instance resolved for I, like
+// a cache or metrics around a Repository, without changing the implementing
+// struct. Decorators are applied in the order they are registered, whenever
+// InstaciateInjected[I], an inject:"struct" or lazy field or an injection
+// method resolves I through the config.
+func Decorate[I any](fn func(I) I) {
+	t := reflect.TypeOf((*I)(nil)).Elem()
+	stateMu.Lock()
+	decorators[t] = append(decorators[t], newDecorator(fn))
+	stateMu.Unlock()
+	logRegistration("decorator", t)
+}
+
+// AddDecorator registers a decorator of I applied only when an interfaces
+// entry of I lists its name under decorators, after the ones registered with
+// Decorate:
+//
+//	interfaces:
+//	  - name: Repository
+//	    package: domain
+//	    injectable: SQLRepository
+//	    decorators: [metrics, cache]
+func AddDecorator[I any](name string, fn func(I) I) {
+	t := reflect.TypeOf((*I)(nil)).Elem()
+	stateMu.Lock()
+	if namedDecorators[t] == nil {
+		namedDecorators[t] = map[string]decorator{}
+	}
+	namedDecorators[t][name] = newDecorator(fn)
+	stateMu.Unlock()
+	logger().Debug("inject: registered", slog.String("kind", "decorator"), slog.String("type", t.String()), slog.String("name", name))
+}
+
+func newDecorator[I any](fn func(I) I) decorator {
+	return func(v reflect.Value) (reflect.Value, error) {
+		var instance I
+		target := reflect.ValueOf(&instance).Elem()
+		switch {
+		case v.Type().AssignableTo(target.Type()):
+			target.Set(v)
+			decorated := fn(instance)
+			return reflect.ValueOf(&decorated).Elem(), nil
+		case v.Kind() == reflect.Pointer && v.Type().Elem().AssignableTo(target.Type()):
+			// structs are built as pointers, decorated by value
+			target.Set(v.Elem())
+			decorated := fn(instance)
+			return reflect.ValueOf(&decorated), nil
+		}
+		return reflect.Value{}, fmt.Errorf("%v is bound to %v, which does not implement it", target.Type(), v.Type())
+	}
+}
+
+// decorate applies the decorators of t to instance: the ones registered with
+// Decorate, then the ones named by the interfaces entry, in order.
+func decorate(t reflect.Type, inter *InterfaceDescription, instance reflect.Value) (reflect.Value, error) {
+	// decorators are looked up under the lock, and called without it
+	stateMu.Lock()
+	chain := append([]decorator(nil), decorators[t]...)
+	var missing string
+	if inter != nil {
+		for _, name := range inter.Decorators {
+			d, ok := namedDecorators[t][name]
+			if !ok {
+				missing = name
+				break
+			}
+			chain = append(chain, d)
+		}
+	}
+	stateMu.Unlock()
+	if missing != "" {
+		return reflect.Value{}, fmt.Errorf("interfaces: %q lists decorator %q, which is not registered (use AddDecorator)", inter.GetPath(), missing)
+	}
+
+	for _, d := range chain {
+		var err error
+		if instance, err = d(instance); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return instance, nil
+}
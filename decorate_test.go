@@ -0,0 +1,69 @@
+package inject
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type prefixPrinter struct {
+	iMessagePrinter
+	prefix string
+}
+
+func (p *prefixPrinter) GetMessage() string {
+	return p.prefix + p.iMessagePrinter.GetMessage()
+}
+
+func prefixWith(prefix string) func(iMessagePrinter) iMessagePrinter {
+	return func(p iMessagePrinter) iMessagePrinter {
+		return &prefixPrinter{iMessagePrinter: p, prefix: prefix}
+	}
+}
+
+type decoratedService struct {
+	Printer iMessagePrinter `inject:"struct"`
+}
+
+func TestDecorate(t *testing.T) {
+
+	init_instances()
+	defer ResetData()
+	ImportConfig("test_files/config_decorators.yaml")
+
+	Decorate(prefixWith("first: "))
+	Decorate(prefixWith("second: "))
+	AddDecorator("cache", prefixWith("cache: "))
+	AddDecorator("metrics", prefixWith("metrics: "))
+
+	expected := "cache: metrics: second: first: " + messagePrinterA_MSG
+
+	printer, err := InstaciateInjected[iMessagePrinter]()
+	if err != nil {
+		t.Fatalf("InstaciateInjected(). Expected no error, got %v", err)
+	}
+	if msg := printer.GetMessage(); msg != expected {
+		t.Fatalf("InstaciateInjected(). Expected %q, got %q", expected, msg)
+	}
+
+	s, err := Instanciate[decoratedService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+	if msg := s.Printer.GetMessage(); msg != expected {
+		t.Fatalf("Instanciate(). Expected %q, got %q", expected, msg)
+	}
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected no error, got %v", err)
+	}
+
+	delete(namedDecorators[reflect.TypeOf((*iMessagePrinter)(nil)).Elem()], "cache")
+	_, err = InstaciateInjected[iMessagePrinter]()
+	if err == nil || !strings.Contains(err.Error(), `decorator "cache"`) {
+		t.Fatalf("InstaciateInjected(). Expected an unknown decorator error, got %v", err)
+	}
+	if err := Validate(); err == nil || !strings.Contains(err.Error(), `decorator "cache"`) {
+		t.Fatalf("Validate(). Expected an unknown decorator error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Explanation reports the chain of decisions that resolves a type through the
+// config, answering why a given implementation was injected.
+type Explanation struct {
+	Type       string                 // the resolved type
+	Name       string                 // named key of the inject tag, if any
+	ConfigFile string                 // file loaded by LoadConfig or ImportConfig
+	Overridden bool                   // replaced by Override, no config decision applies
+	Interface  *InterfaceDescription  // interfaces entry mapping the type, if any
+	Injectable *InjectableDescription // injectables entry chosen, nil when unbound
+	ModeAuto   bool                   // the injectable was chosen by its own mode auto entry
+	Decorators int                    // decorators registered with Decorate
+	Fields     FieldSources           // source of each field set on the instance
+}
+
+// Explain reports how InstaciateInjected[T] resolves T: the config file and
+// interfaces entry mapping it, the injectable chosen, whether mode auto
+// applied, and where the value of each field of the instance came from.
+// The fields are resolved the way InstaciateInjected does to find their
+// sources, but as a dry run: injection methods and decorators aren't called
+// and no singleton is cached, so explaining has no side effects.
+func Explain[T any]() (*Explanation, error) {
+	return explain(reflect.TypeOf((*T)(nil)).Elem(), "")
+}
+
+// ExplainField is like Explain for the inject tagged field of T at path, like
+// Service.Repository, taking into account the name option of its tag.
+func ExplainField[T any](path string) (*Explanation, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	var f reflect.StructField
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("explain %s: %v is not a struct", path, t)
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("explain %s: %v has no field %s", path, t, name)
+		}
+		f, t = field, field.Type
+	}
+	tag, err := parseInjectTag(f)
+	if err != nil {
+		return nil, fmt.Errorf("explain %s: %w", path, err)
+	}
+	if tag.Lazy {
+		t = lazyType(t)
+	} else if !tag.Resolves() {
+		return nil, fmt.Errorf("explain %s: the field is not resolved through the config (use inject:\"struct\")", path)
+	}
+	return explain(t, tag.Name)
+}
+
+func explain(t reflect.Type, name string) (*Explanation, error) {
+	stateMu.Lock()
+	e := &Explanation{
+		Type:       t.String(),
+		Name:       name,
+		ConfigFile: configFile,
+		Decorators: len(decorators[t]),
+	}
+	stateMu.Unlock()
+	if _, ok := overridden(t); ok {
+		e.Overridden = true
+		return e, nil
+	}
+
+	b, err := findBinding(t, name)
+	if err != nil {
+		return nil, err
+	}
+	e.Interface, e.Injectable, e.ModeAuto = b.inter, b.injectable, b.auto
+	if b.injectable == nil {
+		return e, nil
+	}
+
+	in := &injection{sources: FieldSources{}, dryRun: true}
+	if _, err := buildInjectable(t, name, in); err != nil {
+		return nil, err
+	}
+	e.Fields = in.sources
+	return e, nil
+}
+
+// String writes the explanation as indented lines, one per decision.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Type)
+	if e.Name != "" {
+		fmt.Fprintf(&sb, " named %s", e.Name)
+	}
+	sb.WriteString("\n")
+	if e.Overridden {
+		sb.WriteString("  overridden by a fake, the config is not used\n")
+		return sb.String()
+	}
+
+	configFile := e.ConfigFile
+	if configFile == "" {
+		configFile = "(none)"
+	}
+	fmt.Fprintf(&sb, "  config file: %s\n", configFile)
+	if e.Interface != nil {
+		fmt.Fprintf(&sb, "  interfaces entry: %s", e.Interface.GetPath())
+		if e.Interface.Named != "" {
+			fmt.Fprintf(&sb, " named %s", e.Interface.Named)
+		}
+		fmt.Fprintf(&sb, " -> %s\n", e.Interface.Injectable)
+	} else {
+		sb.WriteString("  interfaces entry: (none)\n")
+	}
+	switch {
+	case e.Injectable == nil:
+		sb.WriteString("  injectable: (none), the type is not bound\n")
+	case e.ModeAuto:
+		fmt.Fprintf(&sb, "  injectable: %s, by its own entry with mode auto\n", e.Injectable.GetPath())
+	default:
+		fmt.Fprintf(&sb, "  injectable: %s\n", e.Injectable.GetPath())
+	}
+	if e.Injectable != nil && e.Injectable.Params != nil {
+		fmt.Fprintf(&sb, "  params: %v\n", e.Injectable.Params)
+	}
+
+	var named []string
+	if e.Interface != nil {
+		named = e.Interface.Decorators
+	}
+	if e.Decorators > 0 || len(named) > 0 {
+		fmt.Fprintf(&sb, "  decorators: %d registered with Decorate", e.Decorators)
+		if len(named) > 0 {
+			fmt.Fprintf(&sb, ", then %s", strings.Join(named, ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Fprintf(&sb, "  field %s: %v\n", field, e.Fields[field])
+	}
+	return sb.String()
+}
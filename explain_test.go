@@ -0,0 +1,136 @@
+package inject
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Explain[iMessagePrinter]()
+	if err != nil {
+		t.Fatalf("Explain(). Expected no error, got %v", err)
+	}
+	if e.ConfigFile != "test_files/config_tags.yaml" || e.Interface == nil || e.Injectable.Name != "messagePrinterA" || e.ModeAuto {
+		t.Fatalf("Explain(). Expected the unnamed interfaces entry, got %+v", e)
+	}
+
+	e, err = ExplainField[taggedService]("Secondary")
+	if err != nil {
+		t.Fatalf("ExplainField(). Expected no error, got %v", err)
+	}
+	if e.Interface == nil || e.Interface.Named != "secondary" || e.Injectable.Name != "messagePrinterB" {
+		t.Fatalf("ExplainField(). Expected the named interfaces entry, got %+v", e)
+	}
+	if e.Fields["Message"] != SourceParams {
+		t.Fatalf("ExplainField(). Expected Message from params, got %v", e.Fields)
+	}
+	for _, line := range []string{
+		"inject.iMessagePrinter named secondary\n",
+		"  config file: test_files/config_tags.yaml\n",
+		"  interfaces entry: inject.iMessagePrinter named secondary -> messagePrinterB\n",
+		"  injectable: inject.messagePrinterB\n",
+		"  field Message: params\n",
+	} {
+		if !strings.Contains(e.String(), line) {
+			t.Errorf("String(). Expected %q, got:\n%s", line, e)
+		}
+	}
+
+	e, err = ExplainField[taggedService]("Single")
+	if err != nil {
+		t.Fatalf("ExplainField(). Expected no error, got %v", err)
+	}
+	if e.Interface != nil || !e.ModeAuto || !strings.Contains(e.String(), "by its own entry with mode auto") {
+		t.Fatalf("ExplainField(). Expected mode auto, got %s", e)
+	}
+
+	if _, err := ExplainField[taggedService]("Unknown"); err == nil {
+		t.Fatal("ExplainField(). Expected an error for an unknown field")
+	}
+}
+
+func TestExplainDryRun(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	AddInjectable[taggedService]()
+	// fails the injection if called
+	AddInjectionMethods[messagePrinterA]("Missing")
+	decorated := 0
+	Decorate(func(p iMessagePrinter) iMessagePrinter {
+		decorated++
+		return p
+	})
+	if err := LoadConfig("test_files/config_explain.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Explain[taggedService]()
+	if err != nil {
+		t.Fatalf("Explain(). Expected no error, got %v", err)
+	}
+	if e.Fields["Primary"] != SourceBinding || e.Fields["Single"] != SourceBinding {
+		t.Fatalf("Explain(). Expected the bound fields, got %v", e.Fields)
+	}
+	if decorated != 0 {
+		t.Fatalf("Explain(). Expected no decorator call, got %d", decorated)
+	}
+	if len(singletons) != 0 {
+		t.Fatalf("Explain(). Expected no cached singleton, got %v", singletons)
+	}
+	if e, err := Explain[iMessagePrinter](); err != nil || e.Decorators != 1 {
+		t.Fatalf("Explain(). Expected 1 decorator, got %v, %v", e, err)
+	}
+	if decorated != 0 {
+		t.Fatalf("Explain(). Expected no decorator call, got %d", decorated)
+	}
+}
+
+type explainedItem struct {
+	Name string
+}
+
+var explainedInjections int
+
+func (i *explainedItem) InjectCount() {
+	explainedInjections++
+}
+
+type explainedList struct {
+	Items []explainedItem
+}
+
+func TestExplainDryRunParams(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInjectable[explainedList]()
+	AddInjectionMethods[explainedItem]()
+	config = Config{Injectables: []InjectableDescription{{
+		ComponentPath: ComponentPath{Name: "explainedList", Package: "inject"},
+		InjectMode:    "auto",
+		Params:        map[string]any{"Items": []any{map[string]any{"Name": "a"}}},
+	}}}
+
+	explainedInjections = 0
+	e, err := Explain[explainedList]()
+	if err != nil || e.Fields["Items"] != SourceParams {
+		t.Fatalf("Explain(). Expected Items from params, got %v, %v", e, err)
+	}
+	if explainedInjections != 0 {
+		t.Fatalf("Explain(). Expected no injection method call for the params, got %d", explainedInjections)
+	}
+}
@@ -0,0 +1,96 @@
+package inject
+
+import (
+	"io"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ExportConfig writes the registered interfaces, injectables and factories,
+// merged with the bindings, params and lifetimes of the imported config, using
+// the same yaml schema read by ImportConfig. Interfaces the config doesn't
+// bind are left out.
+func ExportConfig(w io.Writer) error {
+	content, err := yaml.Marshal(exportConfigData(Snapshot()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// exportConfigData builds the exported config from a snapshot, so the
+// registry is read under the lock.
+func exportConfigData(s *RegistrySnapshot) Config {
+	data := Config{Version: ConfigVersion}
+	config := s.config
+
+	for _, t := range sortedTypes(factoryTypes(s.factories)) {
+		description := FactoryDescription{ComponentPath: fullPath(t)}
+		if f, ok := s.factories[t].(iLifetime); ok {
+			description.IsSingleton = f.singleton()
+		}
+		data.Factories = append(data.Factories, description)
+	}
+	for _, f := range config.Factories {
+		if t, _ := s.factoryType(f.ComponentPath); t == nil {
+			data.Factories = append(data.Factories, f)
+		}
+	}
+
+	for _, t := range sortedTypes(registeredTypes(s.injectables)) {
+		description := InjectableDescription{}
+		if i, _ := config.getInjectable(t, registeredTypes(s.injectables)); i != nil {
+			description = *i
+		}
+		description.ComponentPath = fullPath(t)
+		data.Injectables = append(data.Injectables, description)
+	}
+	for _, i := range config.Injectables {
+		if t, _ := s.injectableType(i.ComponentPath); t == nil {
+			data.Injectables = append(data.Injectables, i)
+		}
+	}
+
+	for _, t := range sortedTypes(registeredTypes(s.interfaces)) {
+		var entries []InterfaceDescription
+		for _, i := range config.Interfaces {
+			if !i.matches(t) {
+				continue
+			}
+			if inj, _ := config.BoundInjectable(&i); inj != nil {
+				if it, _ := s.injectableType(inj.ComponentPath); it != nil {
+					i.Injectable = typeKey(it)
+				}
+			}
+			entries = append(entries, i)
+		}
+		// interfaces without a binding are left out, an entry without
+		// injectable would not be valid
+		for _, description := range entries {
+			description.ComponentPath = fullPath(t)
+			data.Interfaces = append(data.Interfaces, description)
+		}
+	}
+	for _, i := range config.Interfaces {
+		if t, _ := s.interfaceType(i.ComponentPath); t == nil {
+			data.Interfaces = append(data.Interfaces, i)
+		}
+	}
+
+	return data
+}
+
+// fullPath names t by the full import path of its package.
+func fullPath(t reflect.Type) ComponentPath {
+	return ComponentPath{Package: t.PkgPath(), Name: t.Name()}
+}
+
+func sortedTypes(types []reflect.Type) []reflect.Type {
+	sort.Slice(types, func(i, j int) bool {
+		return typeKey(types[i]) < typeKey(types[j])
+	})
+	return types
+}
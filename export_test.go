@@ -0,0 +1,134 @@
+package inject
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestExportConfig(t *testing.T) {
+
+	init_instances()
+	ImportConfig("test_files/injection-config.qa.yaml")
+
+	var buffer bytes.Buffer
+	if err := ExportConfig(&buffer); err != nil {
+		t.Fatalf("ExportConfig(). Unexpected error: %v", err)
+	}
+	t.Logf("\n%s", buffer.String())
+
+	data, err := parseConfig("export", buffer.Bytes())
+	if err != nil {
+		t.Fatalf("ExportConfig(). Output can't be imported: %v", err)
+	}
+
+	if len(data.Injectables) != len(injectables) {
+		t.Fatalf("ExportConfig(). Expected %d injectables, got %d", len(injectables), len(data.Injectables))
+	}
+
+	if len(data.Factories) != 1 || data.Factories[0].GetPath() != "github.com/carlosranoya/inject.printerContainer" || data.Factories[0].IsSingleton {
+		t.Fatalf("ExportConfig(). Expected non singleton factory inject.printerContainer, got %v", data.Factories)
+	}
+
+	inter, _ := data.getInterface(reflect.TypeOf((*iMessagePrinter)(nil)).Elem(), registeredTypes(interfaces))
+	if inter == nil || inter.Injectable != "github.com/carlosranoya/inject.messagePrinterC" {
+		t.Fatalf("ExportConfig(). Expected iMessagePrinter bound to messagePrinterC, got %v", inter)
+	}
+
+	inj, _ := data.getInjectable(reflect.TypeOf(messagePrinterC{}), registeredTypes(injectables))
+	params, ok := inj.Params.(map[any]any)
+	if !ok || params["Message"] != "This message is from configuration file - qa" || params["Count"] != 5 {
+		t.Fatalf("ExportConfig(). Expected messagePrinterC params to be kept, got %v", inj.Params)
+	}
+
+	// round trip: the exported file must be importable and valid
+	file := filepath.Join(t.TempDir(), "exported.yaml")
+	if err := os.WriteFile(file, buffer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfig(file); err != nil {
+		t.Fatalf("LoadConfig(%s). Unexpected error: %v", file, err)
+	}
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected exported config to be valid, got %v", err)
+	}
+
+	printerContainer := printerContainer{}
+	Inject(&printerContainer)
+
+	if printer, ok := printerContainer.Printer.(*messagePrinterC); !ok || printer.Count != 5 {
+		t.Fatalf("Inject() with exported config. Expected messagePrinterC with Count 5, got %v", printerContainer.Printer)
+	}
+
+}
+
+func TestExportNamedInterfaces(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	ImportConfig("test_files/config_tags.yaml")
+
+	var buffer bytes.Buffer
+	if err := ExportConfig(&buffer); err != nil {
+		t.Fatalf("ExportConfig(). Unexpected error: %v", err)
+	}
+	data, err := parseConfig("export", buffer.Bytes())
+	if err != nil {
+		t.Fatalf("ExportConfig(). Output can't be imported: %v", err)
+	}
+	inter, _ := data.getNamedInterface(reflect.TypeOf((*iMessagePrinter)(nil)).Elem(), "secondary", registeredTypes(interfaces))
+	if len(data.Interfaces) != 2 || inter == nil || inter.Injectable != "github.com/carlosranoya/inject.messagePrinterB" {
+		t.Fatalf("ExportConfig(). Expected the named entry bound to messagePrinterB, got %v", data.Interfaces)
+	}
+
+	// unbound interfaces are left out, so the export stays valid
+	ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	config = Config{}
+	buffer.Reset()
+	if err := ExportConfig(&buffer); err != nil {
+		t.Fatalf("ExportConfig(). Unexpected error: %v", err)
+	}
+	if data, _ := parseConfig("export", buffer.Bytes()); len(data.Interfaces) != 0 {
+		t.Fatalf("ExportConfig(). Expected no interfaces entry, got %v", data.Interfaces)
+	}
+}
+
+func TestExportConcurrent(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := ExportConfig(io.Discard); err != nil {
+				t.Errorf("ExportConfig(). Expected no error, got %v", err)
+			}
+			Validate()
+		}()
+		go func() {
+			defer wg.Done()
+			AddInjectable[messagePrinterB]()
+		}()
+		go func() {
+			defer wg.Done()
+			if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+				t.Errorf("LoadConfig(). Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,30 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// The functions of this file are the runtime support of the constructors
+// written by injectgen, which live in the wired packages and can't reach the
+// unexported parsing and validation. Programs using the inject package
+// directly don't need them.
+
+// ParseText parses text into a T the way value tags and env variables are
+// parsed: registered converters and decoders first, JSON for collections and
+// structs.
+func ParseText[T any](text string) (T, error) {
+	var result T
+	target := reflect.ValueOf(&result).Elem()
+	v, err := (&injection{}).parseTagValue(text, target.Type())
+	if err != nil {
+		return result, err
+	}
+	target.Set(v)
+	return result, nil
+}
+
+// ValidateStruct checks the validate tags of v, a pointer to a struct, the way
+// the Instanciate and Inject functions do after injection.
+func ValidateStruct(v any) error {
+	return validateFields(reflect.ValueOf(v))
+}
@@ -1,5 +1,5 @@
 module github.com/carlosranoya/inject
 
-go 1.18
+go 1.21
 
 require gopkg.in/yaml.v2 v2.4.0
@@ -0,0 +1,297 @@
+package inject
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Node kinds of a DependencyGraph.
+const (
+	NodeInterface  = "interface"
+	NodeInjectable = "injectable"
+	NodeStruct     = "struct"
+)
+
+// Edge kinds of a DependencyGraph.
+const (
+	EdgeBinds  = "binds"  // interface resolved to an injectable by the config
+	EdgeInject = "inject" // field tagged inject:"struct"
+	EdgeNested = "nested" // inject tagged struct or pointer to struct field
+)
+
+// GraphNode is a type in a DependencyGraph, identified by its full import path
+// and name.
+type GraphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Factory   bool   `json:"factory,omitempty"`
+	Singleton bool   `json:"singleton,omitempty"`
+}
+
+// GraphEdge is a dependency between two nodes of a DependencyGraph.
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Field string `json:"field,omitempty"`
+	Name  string `json:"name,omitempty"` // named key of the binding, from the name option of inject tags
+}
+
+// DependencyGraph is the dependency graph resolved from the registry and
+// the imported config.
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type graphBuilder struct {
+	nodes   map[string]*GraphNode
+	types   map[string]reflect.Type
+	edges   []GraphEdge
+	visited map[reflect.Type]bool
+}
+
+// Graph builds the dependency graph of the registered interfaces, injectables
+// and factories, following every inject tagged field.
+func Graph() *DependencyGraph {
+	b := graphBuilder{
+		nodes:   map[string]*GraphNode{},
+		types:   map[string]reflect.Type{},
+		visited: map[reflect.Type]bool{},
+	}
+	// the registry and config are read from a copy taken under the lock
+	s := Snapshot()
+
+	for _, t := range s.interfaces {
+		b.node(t, NodeInterface)
+	}
+	for _, t := range s.injectables {
+		b.node(t, NodeInjectable)
+		b.walk(t)
+	}
+	for t, f := range s.factories {
+		node := b.node(t, NodeStruct)
+		node.Factory = true
+		if lifetime, ok := f.(iLifetime); ok {
+			node.Singleton = lifetime.singleton()
+		}
+		b.walk(t)
+	}
+
+	// walking may add interface nodes which are bound by the config, with or
+	// without a named key
+	for _, t := range b.interfaceTypes() {
+		names := []string{""}
+		for _, inter := range s.config.Interfaces {
+			if inter.Named != "" && inter.matches(t) {
+				names = append(names, inter.Named)
+			}
+		}
+		for _, name := range names {
+			binding, _ := s.findBinding(t, name)
+			if binding.injectable == nil {
+				continue
+			}
+			it, _ := s.injectableType(binding.injectable.ComponentPath)
+			if it == nil {
+				continue
+			}
+			b.node(it, NodeInjectable)
+			b.edges = append(b.edges, GraphEdge{From: typeKey(t), To: typeKey(it), Kind: EdgeBinds, Name: name})
+			b.walk(it)
+		}
+	}
+
+	return b.graph()
+}
+
+// Graph builds the graph of a config on its own, without the registry: its
+// interfaces, injectables and factories as nodes, identified by the package
+// and name written in the file, and its bindings as edges. Edges of named
+// bindings carry the named key as their name.
+func (c *Config) Graph() *DependencyGraph {
+	nodes := map[string]*GraphNode{}
+	node := func(id string, kind string) *GraphNode {
+		n, ok := nodes[id]
+		if !ok {
+			n = &GraphNode{ID: id, Kind: kind}
+			nodes[id] = n
+		} else if n.Kind == NodeStruct && kind != NodeStruct {
+			n.Kind = kind
+		}
+		return n
+	}
+
+	b := graphBuilder{}
+	for _, inj := range c.Injectables {
+		node(inj.GetPath(), NodeInjectable)
+	}
+	for _, f := range c.Factories {
+		n := node(f.GetPath(), NodeStruct)
+		n.Factory = true
+		n.Singleton = f.IsSingleton
+	}
+	for _, inter := range c.Interfaces {
+		from := node(inter.GetPath(), NodeInterface).ID
+		to := inter.Injectable
+		if inj, _ := c.BoundInjectable(&inter); inj != nil {
+			to = inj.GetPath()
+		}
+		if to != "" {
+			node(to, NodeInjectable)
+			b.edges = append(b.edges, GraphEdge{From: from, To: to, Kind: EdgeBinds, Name: inter.Named})
+		}
+	}
+
+	b.nodes = nodes
+	return b.graph()
+}
+
+func (b *graphBuilder) node(t reflect.Type, kind string) *GraphNode {
+	id := typeKey(t)
+	node, ok := b.nodes[id]
+	if !ok {
+		node = &GraphNode{ID: id, Kind: kind}
+		b.nodes[id] = node
+		b.types[id] = t
+	} else if node.Kind == NodeStruct && kind != NodeStruct {
+		node.Kind = kind
+	}
+	return node
+}
+
+func (b *graphBuilder) walk(t reflect.Type) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || b.visited[t] {
+		return
+	}
+	b.visited[t] = true
+	from := typeKey(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("inject") == "" {
+			continue
+		}
+		to := typeKey(f.Type)
+		if tag, _ := parseInjectTag(f); tag.Resolves() {
+			bound := f.Type
+			if tag.Lazy && lazyType(bound) != nil {
+				bound = lazyType(bound)
+				to = typeKey(bound)
+			}
+			kind := NodeStruct
+			if bound.Kind() == reflect.Interface {
+				kind = NodeInterface
+			}
+			b.node(bound, kind)
+			b.edges = append(b.edges, GraphEdge{From: from, To: to, Kind: EdgeInject, Field: f.Name, Name: tag.Name})
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			b.node(ft, NodeStruct)
+			b.edges = append(b.edges, GraphEdge{From: from, To: to, Kind: EdgeNested, Field: f.Name})
+			b.walk(ft)
+		}
+	}
+}
+
+func (b *graphBuilder) interfaceTypes() []reflect.Type {
+	var types []reflect.Type
+	for id, node := range b.nodes {
+		if node.Kind == NodeInterface {
+			types = append(types, b.types[id])
+		}
+	}
+	return sortedTypes(types)
+}
+
+func (b *graphBuilder) graph() *DependencyGraph {
+	g := &DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, node := range b.nodes {
+		g.Nodes = append(g.Nodes, *node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+
+	seen := map[GraphEdge]bool{}
+	for _, edge := range b.edges {
+		if !seen[edge] {
+			seen[edge] = true
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Name < b.Name
+	})
+	return g
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *DependencyGraph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph inject {"); err != nil {
+		return err
+	}
+	for _, node := range g.Nodes {
+		shape := "box"
+		style := "solid"
+		switch {
+		case node.Kind == NodeInterface:
+			shape = "ellipse"
+		case node.Factory:
+			shape = "component"
+		case node.Kind == NodeStruct:
+			style = "dashed"
+		}
+		if _, err := fmt.Fprintf(w, "  %q [shape=%s, style=%s];\n", node.ID, shape, style); err != nil {
+			return err
+		}
+	}
+	for _, edge := range g.Edges {
+		label := edge.Kind
+		switch {
+		case edge.Field != "" && edge.Name != "":
+			label = edge.Field + " (" + edge.Name + ")"
+		case edge.Field != "":
+			label = edge.Field
+		case edge.Name != "":
+			label = edge.Name
+		}
+		style := "solid"
+		if edge.Kind == EdgeBinds {
+			style = "dashed"
+		}
+		if _, err := fmt.Fprintf(w, "  %q -> %q [label=%q, style=%s];\n", edge.From, edge.To, label, style); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// WriteJSON writes the graph as an indented JSON document.
+func (g *DependencyGraph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
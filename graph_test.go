@@ -0,0 +1,146 @@
+package inject
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func hasEdge(g *DependencyGraph, edge GraphEdge) bool {
+	for _, e := range g.Edges {
+		if e == edge {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGraph(t *testing.T) {
+
+	init_instances()
+	ImportConfig("test_files/injection-config.dev.yaml")
+
+	g := Graph()
+
+	const pkg = "github.com/carlosranoya/inject."
+
+	expected := []GraphEdge{
+		{From: pkg + "printerContainer", To: pkg + "iMessagePrinter", Kind: EdgeInject, Field: "Printer"},
+		{From: pkg + "iMessagePrinter", To: pkg + "messagePrinterD", Kind: EdgeBinds},
+		{From: pkg + "messagePrinterD", To: pkg + "messagePrinterB", Kind: EdgeNested, Field: "SubPrinter"},
+		{From: pkg + "messagePrinterE", To: pkg + "messagePrinterB", Kind: EdgeNested, Field: "SubPrinter"},
+	}
+	for _, edge := range expected {
+		if !hasEdge(g, edge) {
+			t.Fatalf("Graph(). Expected edge %v, got %v", edge, g.Edges)
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT(). Unexpected error: %v", err)
+	}
+	line := `"github.com/carlosranoya/inject.printerContainer" -> "github.com/carlosranoya/inject.iMessagePrinter" [label="Printer", style=solid];`
+	if !strings.Contains(dot.String(), line) {
+		t.Fatalf("WriteDOT(). Expected line %s, got\n%s", line, dot.String())
+	}
+
+	var buffer bytes.Buffer
+	if err := g.WriteJSON(&buffer); err != nil {
+		t.Fatalf("WriteJSON(). Unexpected error: %v", err)
+	}
+	decoded := DependencyGraph{}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON(). Invalid JSON: %v", err)
+	}
+	if len(decoded.Nodes) != len(g.Nodes) || len(decoded.Edges) != len(g.Edges) {
+		t.Fatalf("WriteJSON(). Expected %d nodes and %d edges, got %d and %d", len(g.Nodes), len(g.Edges), len(decoded.Nodes), len(decoded.Edges))
+	}
+
+}
+
+func TestGraphNamedBindings(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	AddInjectable[taggedService]()
+	if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	g := Graph()
+
+	const pkg = "github.com/carlosranoya/inject."
+
+	expected := []GraphEdge{
+		{From: pkg + "taggedService", To: pkg + "iMessagePrinter", Kind: EdgeInject, Field: "Secondary", Name: "secondary"},
+		{From: pkg + "iMessagePrinter", To: pkg + "messagePrinterA", Kind: EdgeBinds},
+		{From: pkg + "iMessagePrinter", To: pkg + "messagePrinterB", Kind: EdgeBinds, Name: "secondary"},
+	}
+	for _, edge := range expected {
+		if !hasEdge(g, edge) {
+			t.Fatalf("Graph(). Expected edge %v, got %v", edge, g.Edges)
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT(). Unexpected error: %v", err)
+	}
+	line := `"github.com/carlosranoya/inject.taggedService" -> "github.com/carlosranoya/inject.iMessagePrinter" [label="Secondary (secondary)", style=solid];`
+	if !strings.Contains(dot.String(), line) {
+		t.Fatalf("WriteDOT(). Expected line %s, got\n%s", line, dot.String())
+	}
+}
+
+func TestConfigGraph(t *testing.T) {
+
+	c, err := ReadConfig("test_files/config_tags.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := c.Graph()
+	if len(g.Nodes) != 3 {
+		t.Fatalf("Graph(). Expected 3 nodes, got %v", g.Nodes)
+	}
+	expected := []GraphEdge{
+		{From: "inject.iMessagePrinter", To: "inject.messagePrinterA", Kind: EdgeBinds},
+		{From: "inject.iMessagePrinter", To: "inject.messagePrinterB", Kind: EdgeBinds, Name: "secondary"},
+	}
+	for _, edge := range expected {
+		if !hasEdge(g, edge) {
+			t.Fatalf("Graph(). Expected edge %v, got %v", edge, g.Edges)
+		}
+	}
+}
+
+func TestGraphConcurrent(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Graph()
+		}()
+		go func() {
+			defer wg.Done()
+			AddInjectable[messagePrinterB]()
+			if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+				t.Errorf("LoadConfig(). Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,14 +0,0 @@
-/**
- * Author: Carlos Ranoya
- * File: init.go
- */
-
-package inject
-
-import (
-	"fmt"
-)
-
-func init() {
-	fmt.Println("inject initializing ...")
-}
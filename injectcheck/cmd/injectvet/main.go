@@ -0,0 +1,15 @@
+// Command injectvet runs the injectcheck analyzer, on its own or from go vet:
+//
+//	go install github.com/carlosranoya/inject/injectcheck/cmd/injectvet@latest
+//	go vet -vettool=$(which injectvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/carlosranoya/inject/injectcheck"
+)
+
+func main() {
+	singlechecker.Main(injectcheck.Analyzer)
+}
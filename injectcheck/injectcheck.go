@@ -0,0 +1,301 @@
+// Package injectcheck defines an analyzer checking the inject, value and env
+// struct tags read by the inject package, which otherwise fail only at run
+// time.
+//
+// It reports:
+//
+//   - inject tags with unknown or conflicting options, and aliases that look
+//     like a misspelled struct option, like inject:"strcut";
+//   - the struct option on fields which are not interfaces or structs, and
+//     lazy fields which are not a func() T or func() (T, error);
+//   - value tags which can't be parsed as the field type, like value:"abc"
+//     on an int field, or invalid JSON for collections and structs;
+//   - tagged fields of kinds the inject package can't set, like channels and
+//     funcs, and unexported fields without the private option.
+//
+// Named types other than time.Duration may have decoders registered at run
+// time, so their values are not checked.
+package injectcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/carlosranoya/inject/internal/tags"
+)
+
+const doc = `check inject, value and env struct tags
+
+The injectcheck analyzer reports inject tags with unknown options or typos,
+value tags that can't be parsed as the type of their field, and tagged fields
+that the inject package can't set.`
+
+// Analyzer checks the struct tags read by the inject package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "injectcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, f := range n.(*ast.StructType).Fields.List {
+			if f.Tag == nil {
+				continue
+			}
+			text, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				continue
+			}
+			tag := reflect.StructTag(text)
+			_, hasInject := tag.Lookup("inject")
+			_, hasValue := tag.Lookup("value")
+			_, hasEnv := tag.Lookup("env")
+			if !hasInject && !hasValue && !hasEnv {
+				continue
+			}
+			t := pass.TypesInfo.TypeOf(f.Type)
+			if t == nil {
+				continue
+			}
+			exported := true
+			if len(f.Names) > 0 {
+				exported = f.Names[0].IsExported()
+			}
+			checkField(pass, f, t, tag, exported)
+		}
+	})
+	return nil, nil
+}
+
+func checkField(pass *analysis.Pass, f *ast.Field, t types.Type, tag reflect.StructTag, exported bool) {
+	inject, err := tags.ParseInject(tag.Get("inject"))
+	if err == nil {
+		err = inject.Check()
+	}
+	if err != nil {
+		pass.Reportf(f.Tag.Pos(), "%v", err)
+		return
+	}
+	if misspelledStruct(inject.Alias) {
+		pass.Reportf(f.Tag.Pos(), "inject tag: alias %q looks like a misspelled struct option", inject.Alias)
+	}
+
+	switch {
+	case inject.Lazy:
+		if !isLazy(t) {
+			pass.Reportf(f.Tag.Pos(), "inject tag: lazy field must be a func() T or func() (T, error), not %s", typeString(pass, t))
+		}
+		return
+	case inject.Struct:
+		if !resolvable(t) {
+			pass.Reportf(f.Tag.Pos(), "inject tag: the struct option resolves interfaces and structs, not %s", typeString(pass, t))
+		}
+	}
+
+	if !supported(t) {
+		pass.Reportf(f.Tag.Pos(), "inject can't set %s fields", typeString(pass, t))
+		return
+	}
+
+	value, hasValue := tag.Lookup("value")
+	_, hasEnv := tag.Lookup("env")
+	if !exported && !inject.Private && (hasValue || hasEnv || inject.Struct) && !isStruct(t) {
+		pass.Reportf(f.Tag.Pos(), `unexported field can't be set, use the private option (inject:"name,private")`)
+	}
+	if hasValue {
+		if err := checkValue(value, t); err != nil {
+			pass.Reportf(f.Tag.Pos(), "value tag: %v", err)
+		}
+	}
+}
+
+// checkValue parses a value tag like the inject package, for the types whose
+// parsing can't be changed at run time.
+func checkValue(value string, t types.Type) error {
+	if isDuration(t) {
+		if _, err := time.ParseDuration(value); err != nil {
+			return err
+		}
+		return nil
+	}
+	if _, named := t.(*types.Named); named {
+		// may have a decoder or registered converter
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return checkBasic(value, u)
+	case *types.Pointer:
+		if value == "nil" || value == "null" {
+			return nil
+		}
+		if _, named := u.Elem().(*types.Named); named {
+			return nil
+		}
+		return checkJSON(value)
+	case *types.Slice, *types.Array, *types.Map, *types.Struct:
+		return checkJSON(value)
+	}
+	return nil
+}
+
+func checkBasic(value string, t *types.Basic) error {
+	text := strings.TrimSpace(value)
+	var err error
+	switch info := t.Info(); {
+	case info&types.IsUnsigned != 0:
+		_, err = strconv.ParseUint(text, 10, bits(t))
+	case info&types.IsInteger != 0:
+		_, err = strconv.ParseInt(text, 10, bits(t))
+	case info&types.IsFloat != 0:
+		_, err = strconv.ParseFloat(text, bits(t))
+	default:
+		return nil
+	}
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return fmt.Errorf("value %s overflows %s", value, t.Name())
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse %q as %s", value, t.Name())
+	}
+	return nil
+}
+
+func checkJSON(value string) error {
+	var data any
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return fmt.Errorf("invalid JSON %q: %v", value, err)
+	}
+	return nil
+}
+
+func bits(t *types.Basic) int {
+	switch t.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	}
+	return 64
+}
+
+// supported reports whether the inject package can set a field of type t.
+func supported(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Chan, *types.Signature:
+		return false
+	case *types.Basic:
+		return u.Info()&types.IsComplex == 0 && u.Kind() != types.UnsafePointer
+	}
+	return true
+}
+
+// resolvable reports whether a field of type t can be resolved through the
+// config bindings: interfaces, structs and pointers to structs.
+func resolvable(t types.Type) bool {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	switch t.Underlying().(type) {
+	case *types.Interface, *types.Struct:
+		return true
+	}
+	return false
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+func isLazy(t types.Type) bool {
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 0 {
+		return false
+	}
+	results := sig.Results()
+	switch results.Len() {
+	case 1:
+		return true
+	case 2:
+		return types.Identical(results.At(1).Type(), types.Universe.Lookup("error").Type())
+	}
+	return false
+}
+
+func isDuration(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Duration"
+}
+
+func typeString(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pass.Pkg))
+}
+
+// misspelledStruct reports whether alias is the word struct with two letters
+// swapped, or with a letter missing or added.
+func misspelledStruct(alias string) bool {
+	const word = "struct"
+	if len(alias) == len(word) {
+		return alias != word && sortedLetters(alias) == sortedLetters(word)
+	}
+	return (len(alias) == len(word)-1 || len(alias) == len(word)+1) && distance(alias, word) == 1
+}
+
+func sortedLetters(s string) string {
+	letters := []byte(s)
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package a
+
+import "time"
+
+type Printer interface{ Print() }
+
+type Level int
+
+type Config struct {
+	Host    string            `inject:"host" value:"localhost"`
+	Port    int               `inject:"port" value:"abc"`  // want `value tag: cannot parse "abc" as int`
+	Small   int8              `inject:"small" value:"300"` // want `value tag: value 300 overflows int8`
+	Ratio   float64           `inject:"ratio" value:"0.5"`
+	Timeout time.Duration     `inject:"timeout" value:"5 s"`  // want `value tag: time: unknown unit`
+	Level   Level             `inject:"level" value:"debug"`  // named types may have converters
+	Hosts   []string          `inject:"hosts" value:"[a, b]"` // want `value tag: invalid JSON`
+	Labels  map[string]string `inject:"labels" value:"{\"a\": \"1\"}"`
+	Next    *Config           `inject:"next" value:"nil"`
+	secret  string            `inject:"secret" value:"x"` // want `unexported field can't be set`
+	token   string            `inject:"token,private" value:"x"`
+}
+
+type Service struct {
+	Printer  Printer                 `inject:"strcut"`               // want `alias "strcut" looks like a misspelled struct option`
+	Backup   Printer                 `inject:"backup,struct,eager"`  // want `inject tag: unknown option "eager"`
+	Count    int                     `inject:"count,struct"`         // want `the struct option resolves interfaces and structs, not int`
+	Scoped   Printer                 `inject:"scoped,scope=request"` // want `optional, name and scope need the struct or lazy option`
+	Lazy     func() Printer          `inject:"lazy,lazy"`
+	LazyErr  func() (Printer, error) `inject:"lazyErr,lazy"`
+	BadLazy  Printer                 `inject:"badLazy,lazy"` // want `lazy field must be a func\(\) T or func\(\) \(T, error\), not Printer`
+	Events   chan string             `inject:"events"`       // want `inject can't set chan string fields`
+	Strict   bool                    `inject:"strict" value:"yes"`
+	Config   Config                  `inject:"config"`
+	Resolved *Config                 `inject:"struct"`
+	Handler  func(string)            `env:"HANDLER"` // want `inject can't set func\(string\) fields`
+}
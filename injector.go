@@ -4,14 +4,66 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"reflect"
-	"strconv"
-	"strings"
 	"unsafe"
+
+	"github.com/carlosranoya/inject/internal/tags"
 )
 
 type Args map[string]any
 
+// Source identifies where the final value of an injected field came from.
+//
+// Sources are applied in layers, each one overriding the previous ones:
+// value tag < config params < env variable < Args (or positional args).
+type Source int
+
+const (
+	SourceNone    Source = iota // the field was not set
+	SourceTag                   // value tag, or the value tag of the enclosing struct field
+	SourceBinding               // inject:"struct" field resolved through the config
+	SourceParams                // params of the injectables entry in the config
+	SourceEnv                   // environment variable named by the env tag
+	SourceArgs                  // Args or positional args given by the caller
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceTag:
+		return "tag"
+	case SourceBinding:
+		return "binding"
+	case SourceParams:
+		return "params"
+	case SourceEnv:
+		return "env"
+	case SourceArgs:
+		return "args"
+	}
+	return "none"
+}
+
+// FieldSources maps the path of each injected field (like DB.Host) to the
+// source of its final value.
+type FieldSources map[string]Source
+
+// injection holds the layers of values applied to the fields of one struct.
+type injection struct {
+	args       Args
+	positional []any
+	defaults   Args // from the JSON value tag of the enclosing struct field
+	params     any  // config params of the injectable being built
+	remap      bool
+	overlay    bool // only apply params and env over the current values
+	path       string
+	sources    FieldSources
+	scoped     map[scopeKey]reflect.Value // instances of the request scope
+	dryRun     bool                       // only resolve the fields, for Explain
+	checkOnly  bool                       // only check conversions, injecting nothing, for Validate
+}
+
 func Instanciate[T any]() (*T, error) {
 	return InstanciateWithArgs[T](nil, false)
 }
@@ -21,22 +73,25 @@ func InstanciateWithRemap[T any]() (*T, error) {
 }
 
 func InstanciateWithArgs[T any](args Args, remap bool) (*T, error) {
-	var t T
-	Type := reflect.TypeOf(t)
-	if Type.Kind() != reflect.Pointer {
-		Type = reflect.PointerTo(Type)
-	}
+	return instanciate[T](&injection{args: args, remap: remap})
+}
 
-	v := reflect.New(Type.Elem())
+func InstanciateWithPositionalArgs[T any](args []any) (*T, error) {
+	return instanciate[T](&injection{positional: args})
+}
 
-	err := injectWithValueAndArgs(v, args, nil, remap)
+// InstanciateWithSources is like InstanciateWithArgs, and also reports which
+// source supplied the final value of each injected field.
+func InstanciateWithSources[T any](args Args, remap bool) (*T, FieldSources, error) {
+	sources := FieldSources{}
+	obj, err := instanciate[T](&injection{args: args, remap: remap, sources: sources})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return v.Interface().(*T), nil
+	return obj, sources, nil
 }
 
-func InstanciateWithPositionalArgs[T any](args []any) (*T, error) {
+func instanciate[T any](in *injection) (*T, error) {
 	var t T
 	Type := reflect.TypeOf(t)
 	if Type.Kind() != reflect.Pointer {
@@ -45,14 +100,46 @@ func InstanciateWithPositionalArgs[T any](args []any) (*T, error) {
 
 	v := reflect.New(Type.Elem())
 
-	err := injectWithValueAndArgs(v, nil, args, false)
+	if err := planTags(Type); err != nil {
+		return nil, err
+	}
+	err := in.inject(v)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateFields(v); err != nil {
+		return nil, err
+	}
 	return v.Interface().(*T), nil
 }
 
-func injectWithValueAndArgs(v reflect.Value, args Args, positional []any, doRemap bool) error {
+func (in *injection) child(name string) *injection {
+	return &injection{remap: in.remap, overlay: in.overlay, path: in.fieldPath(name), sources: in.sources, scoped: in.scoped, dryRun: in.dryRun}
+}
+
+func (in *injection) fieldPath(name string) string {
+	if in.path == "" {
+		return name
+	}
+	return in.path + "." + name
+}
+
+func (in *injection) record(name string, source Source) {
+	if in.sources != nil && source != SourceNone {
+		in.sources[in.fieldPath(name)] = source
+	}
+}
+
+// argKey is the key used to look up a field in Args: its inject tag when
+// remapping, or its name.
+func (in *injection) argKey(f reflect.StructField) string {
+	if tag, _ := parseInjectTag(f); in.remap && tag.Alias != "" {
+		return tag.Alias
+	}
+	return f.Name
+}
+
+func (in *injection) inject(v reflect.Value) error {
 
 	t := v.Type()
 
@@ -66,334 +153,436 @@ func injectWithValueAndArgs(v reflect.Value, args Args, positional []any, doRema
 	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
 		v = v.Elem()
 	}
+	if in.scoped == nil {
+		in.scoped = map[scopeKey]reflect.Value{}
+	}
 
-	var remap map[string]string
-	var reverse map[string]string
-	if doRemap {
-		remap = make(map[string]string)
-		reverse = make(map[string]string)
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-			tag := f.Tag
-			injectFieldName := tag.Get("inject")
-			if injectFieldName == "" {
-				continue
-			}
-			if f.Name != injectFieldName {
-				remap[f.Name] = injectFieldName
-				reverse[injectFieldName] = f.Name
-			}
-		}
-		if len(remap) == 0 {
-			remap = nil
-		}
+	params, err := paramsByField(t, in.params)
+	if err != nil {
+		return err
 	}
 
 	for i := 0; i < t.NumField(); i++ {
-
-		field := v.Field(i)
-
 		f := t.Field(i)
-		var fieldValue reflect.Value
+		param, hasParam := params[i]
+		_, hasDefault := in.defaults[in.argKey(f)]
 
-		tag := f.Tag
-		injectFieldName := tag.Get("inject")
-		if injectFieldName == "" {
+		if f.Tag.Get("inject") == "" && f.Tag.Get("env") == "" && !hasParam && !hasDefault {
 			continue
 		}
+		if in.overlay && f.Tag.Get("env") == "" && !hasParam {
+			continue
+		}
+		if err := in.injectField(v, i, param, hasParam); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
 
-		// TODO: implement option modes for injection with value of tag "inject"
-
-		value := tag.Get("value")
+	if !in.overlay && !in.dryRun {
+		return in.callInjectionMethods(v)
+	}
+	return nil
+}
 
-		var dat = make(map[string]interface{})
-		var slice []interface{}
+// injectField resolves field i of v layer by layer. Each layer that has a
+// value for the field overrides the previous ones.
+func (in *injection) injectField(v reflect.Value, i int, param any, hasParam bool) error {
+	f := v.Type().Field(i)
+	tag, err := parseInjectTag(f)
+	if err == nil {
+		err = checkInjectTag(tag, f.Type)
+	}
+	if err != nil {
+		return err
+	}
+	field := v.Field(i)
+	exported := field.CanSet()
+	if !exported {
+		// unexported fields are reached through their address. Nested structs
+		// are always filled with their defaults, but other values are only set
+		// with the private option.
+		field = reflect.NewAt(f.Type, unsafe.Pointer(field.UnsafeAddr())).Elem()
+	}
 
+	var fieldValue reflect.Value
+	source := SourceNone
+
+	// 1. value tag, nested struct defaults and config bindings
+	value := f.Tag.Get("value")
+	kind := f.Type.Kind()
+	switch {
+	case in.overlay:
+		// keep the current value
+	case kind == reflect.Struct && !hasDecoder(f.Type):
+		child := in.child(f.Name)
 		if value != "" {
-
-			switch f.Type.Kind() {
-
-			case reflect.String:
-				fieldValue = reflect.ValueOf(value)
-
-			case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8, reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-				value = strings.Trim(value, " ")
-				var number, err = strconv.Atoi(value)
-				if err != nil {
-					panic(err)
-				}
-				fieldValue = reflect.ValueOf(number)
-
-			case reflect.Float64:
-				value = strings.Trim(value, " ")
-				var number, err = strconv.ParseFloat(value, 64)
-				if err != nil {
-					panic(err)
-				}
-				fieldValue = reflect.ValueOf(number)
-
-			case reflect.Bool:
-				value = strings.Trim(value, " ")
-				value = strings.ToLower(value)
-				b := !(value == "false" || value == "0" || value == "nil" || value == "none" || value == "null" || value == "")
-				fieldValue = reflect.ValueOf(b)
-			case reflect.Array | reflect.Slice:
-				if err := json.Unmarshal([]byte(value), &slice); err != nil {
-					panic(err)
-				}
-				fieldValue = reflect.ValueOf(slice)
-			case reflect.Map:
-				if err := json.Unmarshal([]byte(value), &dat); err != nil {
-					panic(err)
-				}
-				fieldValue = reflect.ValueOf(dat)
-
-			case reflect.Struct:
-
-				if err := json.Unmarshal([]byte(value), &dat); err != nil {
-					panic(err)
-				}
-				rf := v.Field(i)
-				rf = reflect.NewAt(rf.Type(), unsafe.Pointer(rf.UnsafeAddr())).Elem()
-				injectWithValueAndArgs(rf, dat, nil, doRemap)
-
-			case reflect.Pointer:
-
-				if value == "nil" || value == "null" {
-					break
-				}
-				if err := json.Unmarshal([]byte(value), &dat); err != nil {
-					panic(err)
-				}
-				rf := reflect.New(f.Type.Elem())
-				injectWithValueAndArgs(rf.Elem(), dat, nil, doRemap)
-				fieldValue = rf
-
-			default:
-				// TODO: warning message - not supported types
+			if err := json.Unmarshal([]byte(value), &child.defaults); err != nil {
+				return err
 			}
-
-		} else if f.Type.Kind() == reflect.Struct {
-			rf := v.Field(i)
-			rf = reflect.NewAt(rf.Type(), unsafe.Pointer(rf.UnsafeAddr())).Elem()
-			injectWithValueAndArgs(rf, nil, nil, doRemap)
-		} else if f.Type.Kind() == reflect.Pointer {
-			rf := reflect.New(f.Type.Elem())
-			injectWithValueAndArgs(rf.Elem(), nil, nil, doRemap)
-			fieldValue = rf
+			source = SourceTag
+		}
+		if err := child.inject(field); err != nil {
+			return err
 		}
 
-		path := f.Type.String()
-		if f.Type.PkgPath() == "" || f.Type.Name() == "" {
-			path = fmt.Sprint(f.Type)
-			if f.Type.Kind() == reflect.Pointer {
-				path = path[1:]
+	case kind == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct && !hasDecoder(f.Type):
+		if value == "nil" || value == "null" {
+			break
+		}
+		child := in.child(f.Name)
+		if value != "" {
+			if err := json.Unmarshal([]byte(value), &child.defaults); err != nil {
+				return err
 			}
+			source = SourceTag
 		}
+		rf := reflect.New(f.Type.Elem())
+		if err := child.inject(rf); err != nil {
+			return err
+		}
+		fieldValue = rf
 
-		if injectFieldName == "struct" {
-			descriptor := getInjectable(path)
-			if descriptor != nil {
-
-				it := getInjectableType(descriptor.GetPath())
+	case value != "":
+		fieldValue, err = in.parseTagValue(value, f.Type)
+		if err != nil {
+			return err
+		}
+		source = SourceTag
+	}
 
-				if it != nil {
-					fieldValue = reflect.New(it)
-				}
+	if d, ok := in.defaults[in.argKey(f)]; ok {
+		fieldValue, err = in.convertValue(d, f.Type)
+		if err != nil {
+			return err
+		}
+		source = SourceTag
+	}
 
-				v, ok := findDeeperStruct(fieldValue)
-				if ok {
-					error := injectWithValueAndArgs(v, dat, slice, doRemap)
-					if error != nil {
-						return error
-					}
-				}
+	if tag.Lazy && !in.overlay {
+		fieldValue = in.child(f.Name).lazyBinding(f.Type, tag)
+		source = SourceBinding
+	} else if tag.Resolves() && !in.overlay {
+		instance, err := in.child(f.Name).resolveBinding(f.Type, tag)
+		if err != nil {
+			return err
+		}
+		if instance.IsValid() {
+			fieldValue = instance
+			source = SourceBinding
+		}
+	}
 
-				if descriptor.Params != nil {
-					argsValue := reflect.ValueOf(descriptor.Params)
-					fillValueWithData(argsValue, fieldValue, reverse)
-				}
-			}
+	// 2. config params
+	if hasParam && isNestedParam(f.Type, param) {
+		fieldValue, err = in.overlayParams(f, field, fieldValue, param)
+		if err != nil {
+			return err
 		}
+		source = SourceParams
+	} else if hasParam {
+		fieldValue, err = in.convertValue(param, f.Type)
+		if err != nil {
+			return fmt.Errorf("params: %w", err)
+		}
+		source = SourceParams
+	}
 
-		k := f.Type.Kind()
-		if fieldValue.IsValid() ||
-			(k != reflect.Struct && k != reflect.Pointer && k != reflect.UnsafePointer && k != reflect.Func) {
-			if len(slice) > 0 {
-				setFieldValue(f.Name, field, fieldValue, args, i, slice, remap)
-			} else {
-				setFieldValue(f.Name, field, fieldValue, args, i, positional, remap)
+	// 3. env variable
+	if name := f.Tag.Get("env"); name != "" {
+		if text, ok := os.LookupEnv(name); ok {
+			fieldValue, err = in.parseTagValue(text, f.Type)
+			if err != nil {
+				return fmt.Errorf("env %s: %w", name, err)
 			}
+			source = SourceEnv
 		}
+	}
 
+	// 4. args given by the caller
+	if arg, ok := in.args[in.argKey(f)]; ok {
+		fieldValue, err = in.convertValue(arg, f.Type)
+		if err != nil {
+			return err
+		}
+		source = SourceArgs
+	} else if i < len(in.positional) {
+		fieldValue, err = in.convertValue(in.positional[i], f.Type)
+		if err != nil {
+			return err
+		}
+		source = SourceArgs
 	}
 
+	if fieldValue.IsValid() {
+		if !exported && !tag.Private {
+			return errors.New(`unexported field can't be set, use the private option (inject:"name,private")`)
+		}
+		if kind == reflect.Struct && fieldValue.Kind() == reflect.Pointer {
+			fieldValue = fieldValue.Elem()
+		}
+		field.Set(fieldValue)
+	}
+	in.record(f.Name, source)
+	if source != SourceNone {
+		logger().Debug("inject: field set", slog.String("field", in.fieldPath(f.Name)), slog.String("source", source.String()))
+	}
 	return nil
 }
 
-func InstaciateInjected[T interface{}]() (T, error) {
+// isNestedParam reports whether param is a map of params for the fields of a
+// struct or pointer to struct, instead of a value for the whole field.
+func isNestedParam(t reflect.Type, param any) bool {
+	if param == nil || reflect.TypeOf(param).Kind() != reflect.Map || hasDecoder(t) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
 
-	w := interfaceWrapper[T]{}
-	name := reflect.TypeOf(w.pointer).Elem().String()
+// overlayParams applies nested params over the current value of a struct or
+// pointer to struct field, keeping the fields they don't mention.
+func (in *injection) overlayParams(f reflect.StructField, field reflect.Value, fieldValue reflect.Value, params any) (reflect.Value, error) {
+	var target reflect.Value
+	if f.Type.Kind() == reflect.Pointer {
+		switch {
+		case fieldValue.IsValid() && !fieldValue.IsNil():
+			target = fieldValue
+		case !field.IsNil():
+			target = field
+		default:
+			target = reflect.New(f.Type.Elem())
+		}
+	} else {
+		target = reflect.New(f.Type)
+		if fieldValue.IsValid() {
+			target.Elem().Set(reflect.Indirect(fieldValue))
+		} else {
+			target.Elem().Set(field)
+		}
+	}
 
-	descriptor := getInjectable(name)
-	if descriptor != nil {
+	child := in.child(f.Name)
+	child.overlay = true
+	child.params = params
+	if err := child.inject(target); err != nil {
+		return reflect.Value{}, err
+	}
+	return target, nil
+}
 
-		it := getInjectableType(descriptor.GetPath())
+// buildInjectable creates the injectable bound to t by the config and
+// injects it with the params of its injectables entry.
+func buildInjectable(t reflect.Type, name string, in *injection) (reflect.Value, error) {
+	if fake, ok := overridden(t); ok {
+		logger().Debug("inject: binding overridden", slog.String("type", t.String()))
+		return fake, nil
+	}
+	b, err := findBinding(t, name)
+	if err != nil || b.injectable == nil {
+		return reflect.Value{}, err
+	}
+	descriptor, inter := b.injectable, b.inter
+	if !b.auto {
+		logger().Debug("inject: binding", slog.String("type", t.String()), slog.String("name", name),
+			slog.String("interface", inter.GetPath()), slog.String("injectable", descriptor.GetPath()))
+	} else {
+		logger().Debug("inject: binding", slog.String("type", t.String()),
+			slog.String("injectable", descriptor.GetPath()), slog.String("mode", "auto"))
+	}
+	it, err := getInjectableType(descriptor.ComponentPath)
+	if err != nil || it == nil {
+		return reflect.Value{}, err
+	}
+	if !reflect.PointerTo(it).AssignableTo(t) && !it.AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("%v is bound to %v, which does not implement it", t, reflect.PointerTo(it))
+	}
+	if err := planTags(it); err != nil {
+		return reflect.Value{}, err
+	}
 
-		if it != nil {
-			tt := reflect.New(it)
-			if descriptor.Params != nil {
-				argsValue := reflect.ValueOf(descriptor.Params)
-				fillValueWithData(argsValue, tt, nil)
-			}
-			return tt.Interface().(T), nil
+	instance := reflect.New(it)
+	if it.Kind() == reflect.Struct {
+		in.params = descriptor.Params
+		if err := in.inject(instance); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", descriptor.GetPath(), err)
 		}
 	}
+	if in.dryRun {
+		return instance, nil
+	}
+	return decorate(t, inter, instance)
+}
 
-	return *w.pointer, errors.New("not registered interface")
+// scopeKey identifies the instances cached by the request and singleton scopes.
+type scopeKey struct {
+	t    reflect.Type
+	name string
+}
 
+var singletons map[scopeKey]reflect.Value = make(map[scopeKey]reflect.Value)
+
+// resolveBinding resolves a struct field of type t through the config
+// bindings, reusing the instance cached for the scope of the tag.
+func (in *injection) resolveBinding(t reflect.Type, tag injectTag) (reflect.Value, error) {
+	if fake, ok := overridden(t); ok {
+		logger().Debug("inject: binding overridden", slog.String("type", t.String()))
+		return fake, nil
+	}
+	stateMu.Lock()
+	var cache map[scopeKey]reflect.Value
+	switch tag.Scope {
+	case ScopeRequest:
+		cache = in.scoped
+	case ScopeSingleton:
+		cache = singletons
+	}
+	key := scopeKey{t: t, name: tag.Name}
+	instance, ok := cache[key]
+	stateMu.Unlock()
+	if ok {
+		logger().Debug("inject: binding reused", slog.String("type", t.String()), slog.String("name", tag.Name), slog.String("scope", tag.Scope))
+		return instance, nil
+	}
+
+	instance, err := buildInjectable(t, tag.Name, in)
+	if err == nil && !instance.IsValid() {
+		err = &unboundError{t: t}
+	}
+	if err != nil {
+		// only the binding of the field itself is optional, not the ones of
+		// the injectable built for it
+		if _, unbound := err.(*unboundError); unbound && tag.Optional {
+			return reflect.Value{}, nil
+		}
+		return reflect.Value{}, err
+	}
+	if in.dryRun && tag.Scope == ScopeSingleton {
+		// leave the singleton to the first real resolution
+		return instance, nil
+	}
+	if instance.IsValid() && cache != nil {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		if cached, ok := cache[key]; ok {
+			// built concurrently, keep the first instance
+			return cached, nil
+		}
+		cache[key] = instance
+	}
+	return instance, nil
 }
 
-func setFieldValue(fieldName string, field reflect.Value, fieldValue reflect.Value, args Args, index int, positional []any, remap map[string]string) {
-	if args != nil {
-		if remap != nil {
-			if v, ok := remap[fieldName]; ok {
-				fieldName = v
-			}
+// lazyBinding returns a func of type ft resolving its binding on each call.
+// A func() T returns the zero value of T when the binding can't be resolved,
+// a func() (T, error) also returns the error, unless the field is optional
+// and the type is not bound.
+func (in *injection) lazyBinding(ft reflect.Type, tag injectTag) reflect.Value {
+	t := lazyType(ft)
+	return reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		result := reflect.Zero(t)
+		instance, err := in.resolveBinding(t, tag)
+		switch {
+		case err != nil:
+		case instance.IsValid() && t.Kind() == reflect.Struct:
+			result = instance.Elem()
+		case instance.IsValid():
+			result = instance
 		}
-		if v, ok := args[fieldName]; ok {
-			switch field.Type().Kind() {
-			case reflect.Int:
-
-				i, ok := v.(int)
-				if ok {
-					field.SetInt(int64(i))
-					break
-				}
-				x, ok := v.(float64)
-				if ok {
-					field.SetInt(int64(x))
-					break
-				}
-
-				field.SetInt(v.(int64))
-
-			case reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8, reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-				field.SetInt(v.(int64))
-			case reflect.Float32, reflect.Float64:
-				field.SetFloat(v.(float64))
-			case reflect.Bool:
-				if v != nil {
-					x, ok := v.(float64)
-					if ok {
-						field.SetBool(x != 0)
-						break
-					}
-					s, ok := v.(string)
-					if ok {
-						field.SetBool(s != "" && s != "false")
-					}
-					n, ok := v.(int64)
-					if ok {
-						field.SetBool(n != 0)
-						break
-					}
-					b, ok := v.(bool)
-					if ok {
-						field.SetBool(b)
-						break
-					}
-				}
-				field.SetBool(v != nil)
-			default:
-				field.Set(reflect.ValueOf(v))
-			}
-			return
+		if ft.NumOut() == 1 {
+			return []reflect.Value{result}
+		}
+		errValue := reflect.Zero(errorType)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
 		}
+		return []reflect.Value{result, errValue}
+	})
+}
+
+// paramsByField assigns config params to field indexes: lists by position,
+// maps by key and scalars to the first field, see tags.ParamsByField.
+func paramsByField(t reflect.Type, params any) (map[int]any, error) {
+	return tags.ParamsByField(tagFields(t), t.String(), params)
+}
+
+func InstaciateInjected[T interface{}]() (T, error) {
+
+	w := interfaceWrapper[T]{}
+	var zero T
+
+	instance, err := buildInjectable(reflect.TypeOf(w.pointer).Elem(), "", &injection{})
+	if err != nil {
+		return zero, err
 	}
-	if positional != nil && index >= 0 && index < len(positional) {
-		field.Set(reflect.ValueOf(positional[index]))
-		return
+	if !instance.IsValid() {
+		return zero, errors.New("not registered interface")
 	}
-	if fieldValue.IsValid() {
-		if field.Type().Kind() == reflect.Struct {
-			field.Set(fieldValue.Elem())
-		} else {
-			field.Set(fieldValue)
-		}
+	if err := validateFields(instance); err != nil {
+		return zero, err
+	}
+	if instance.Kind() == reflect.Interface && instance.IsNil() {
+		// a nil fake set by Override
+		return zero, nil
+	}
+	result, ok := instance.Interface().(T)
+	if !ok {
+		return zero, fmt.Errorf("%v is bound to %v, which does not implement it", reflect.TypeOf(w.pointer).Elem(), instance.Type())
 	}
+	return result, nil
+
 }
 
-func findDeeperStruct(v reflect.Value) (reflect.Value, bool) {
-	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
-		return findDeeperStruct(v.Elem())
-	} else if v.Kind() == reflect.Struct {
-		return v, true
+// parseTagValue parses the text of a value tag or env variable into type t.
+// Collections and structs are written as JSON.
+func (in *injection) parseTagValue(text string, t reflect.Type) (reflect.Value, error) {
+	if hasDecoder(t) {
+		return parseValue(text, t)
 	}
-	return v, false
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		var data any
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			return reflect.Value{}, err
+		}
+		return in.convertValue(data, t)
+	}
+	return parseValue(text, t)
 }
 
 func InjectWithArgs(obj any, args Args, doRemap bool) error {
-	v := reflect.ValueOf(obj)
-	k := v.Kind()
-	if k != reflect.Pointer {
-		return errors.New("object must me a pointer to a struct")
-	}
-	return injectWithValueAndArgs(v, args, nil, doRemap)
+	return injectObject(obj, &injection{args: args, remap: doRemap})
 }
 
 func InjectWithPositionalArgs(obj any, args []any) error {
+	return injectObject(obj, &injection{positional: args})
+}
+
+// InjectWithSources is like InjectWithArgs, and also reports which source
+// supplied the final value of each injected field.
+func InjectWithSources(obj any, args Args, doRemap bool) (FieldSources, error) {
+	sources := FieldSources{}
+	if err := injectObject(obj, &injection{args: args, remap: doRemap, sources: sources}); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func injectObject(obj any, in *injection) error {
 	v := reflect.ValueOf(obj)
 	if v.Kind() != reflect.Pointer {
-		fmt.Printf("--- kind:%v", v.Kind())
 		return errors.New("object must me a pointer to a struct")
 	}
-
-	return injectWithValueAndArgs(v, nil, args, false)
+	if err := planTags(v.Type()); err != nil {
+		return err
+	}
+	if err := in.inject(v); err != nil {
+		return err
+	}
+	return validateFields(v)
 }
 
 func Inject(obj any) error {
 	return InjectWithArgs(obj, nil, false)
 }
-
-func fillValueWithData(data reflect.Value, fieldValue reflect.Value, remap map[string]string) {
-
-	kind := data.Kind()
-	elem := fieldValue.Elem()
-	switch kind {
-	case reflect.Array | reflect.Slice:
-		for i := 0; i < data.Len(); i++ {
-
-			elemField := elem.Field(i)
-			v := data.Index(i)
-			if elemField.CanSet() {
-				elemField.Set(v.Elem())
-			}
-
-		}
-	case reflect.Map:
-		iter := data.MapRange()
-		for iter.Next() {
-
-			k := iter.Key().Elem().String()
-			if remap != nil {
-				k = remap[k]
-			}
-			v := iter.Value()
-			elemField := elem.FieldByName(k)
-
-			if elemField.CanSet() {
-				elemField.Set(v.Elem())
-			}
-
-		}
-	default:
-		if elem.NumField() > 0 {
-			elemField := elem.Field(0)
-			if elemField.CanSet() {
-				elemField.Set(data)
-			}
-		}
-	}
-}
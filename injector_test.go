@@ -1,6 +1,7 @@
 package inject
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -302,3 +303,189 @@ func TestInstanciateCompoundStructWithPointer(t *testing.T) {
 	}
 
 }
+
+func TestInstanciateWithNumericKinds(t *testing.T) {
+
+	type testStruct struct {
+		Int8Field    int8    `inject:"int8" value:"-8"`
+		Uint16Field  uint16  `inject:"uint16" value:"16"`
+		Float32Field float32 `inject:"float32" value:"3.25"`
+		Int64Field   int64   `inject:"int64"`
+		UintField    uint    `inject:"uint"`
+	}
+
+	testObject, err := Instanciate[testStruct]()
+
+	if err != nil {
+		t.Fatalf("Error calling Instanciate: %v", err)
+	}
+
+	if testObject.Int8Field != -8 || testObject.Uint16Field != 16 || testObject.Float32Field != 3.25 {
+		t.Fatalf("tested object numeric fields: injection failed. testObject = %+v", testObject)
+	}
+
+	var args map[string]any = map[string]any{
+		"int8":    float64(100),
+		"uint16":  int64(65535),
+		"float32": 7,
+		"int64":   42,
+		"uint":    12.0,
+	}
+
+	testObject, err = InstanciateWithArgs[testStruct](args, true)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithArgs: %v", err)
+	}
+
+	expected := testStruct{100, 65535, 7, 42, 12}
+	if *testObject != expected {
+		t.Fatalf("tested object numeric fields: injection failed. testObject = %+v, expected %+v", *testObject, expected)
+	}
+
+	args["int8"] = 1000
+
+	_, err = InstanciateWithArgs[testStruct](args, true)
+
+	if err == nil {
+		t.Fatalf("InstanciateWithArgs with overflowing int8 arg. Expected error, got nil")
+	}
+
+	type overflowStruct struct {
+		Uint8Field uint8 `inject:"true" value:"-1"`
+	}
+
+	_, err = Instanciate[overflowStruct]()
+
+	if err == nil {
+		t.Fatalf("Instanciate with negative uint8 value tag. Expected error, got nil")
+	}
+
+}
+
+func TestInstanciateWithTypedCollections(t *testing.T) {
+
+	type point struct {
+		X int `inject:"x"`
+		Y int
+	}
+
+	type testStruct struct {
+		Names   []string         `inject:"names" value:"[\"a\", \"b\", \"c\"]"`
+		Ports   map[string]int   `inject:"ports" value:"{\"http\": 80, \"https\": 443}"`
+		Codes   map[int]string   `inject:"codes" value:"{\"200\": \"ok\", \"404\": \"not found\"}"`
+		Points  []point          `inject:"points" value:"[{\"x\": 1, \"Y\": 2}, {\"x\": 3}]"`
+		Refs    []*point         `inject:"refs" value:"[{\"x\": 5, \"Y\": 6}]"`
+		Triple  [3]uint8         `inject:"triple" value:"[1, 2]"`
+		Nested  map[string][]int `inject:"nested" value:"{\"odd\": [1, 3], \"even\": [2]}"`
+		Untyped []any            `inject:"untyped" value:"[1, \"two\"]"`
+	}
+
+	testObject, err := Instanciate[testStruct]()
+
+	if err != nil {
+		t.Fatalf("Error calling Instanciate: %v", err)
+	}
+
+	expected := testStruct{
+		Names:   []string{"a", "b", "c"},
+		Ports:   map[string]int{"http": 80, "https": 443},
+		Codes:   map[int]string{200: "ok", 404: "not found"},
+		Points:  []point{{1, 2}, {3, 0}},
+		Refs:    []*point{{5, 6}},
+		Triple:  [3]uint8{1, 2, 0},
+		Nested:  map[string][]int{"odd": {1, 3}, "even": {2}},
+		Untyped: []any{1.0, "two"},
+	}
+
+	if !reflect.DeepEqual(*testObject, expected) {
+		t.Fatalf("tested object collection fields: injection failed.\ntestObject = %+v\nexpected   = %+v", *testObject, expected)
+	}
+
+	var args map[string]any = map[string]any{
+		"Names": []any{"x", "y"},
+		"Ports": map[any]any{"grpc": 9090},
+	}
+
+	testObject, err = InstanciateWithArgs[testStruct](args, false)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithArgs: %v", err)
+	}
+
+	if !reflect.DeepEqual(testObject.Names, []string{"x", "y"}) || testObject.Ports["grpc"] != 9090 {
+		t.Fatalf("tested object collection fields from args: injection failed. testObject = %+v", *testObject)
+	}
+
+	type invalidStruct struct {
+		Triple [2]int `inject:"triple" value:"[1, 2, 3]"`
+	}
+
+	if _, err := Instanciate[invalidStruct](); err == nil {
+		t.Fatalf("Instanciate with too many array values. Expected error, got nil")
+	}
+
+	type invalidElements struct {
+		Ports []uint16 `inject:"ports" value:"[80, -1]"`
+	}
+
+	if _, err := Instanciate[invalidElements](); err == nil {
+		t.Fatalf("Instanciate with invalid slice element. Expected error, got nil")
+	}
+
+}
+
+func TestInstanciateWithEnv(t *testing.T) {
+
+	type testStruct struct {
+		Port    uint16         `inject:"port" value:"8080" env:"INJECT_TEST_PORT"`
+		Host    string         `inject:"host" value:"localhost" env:"INJECT_TEST_HOST"`
+		Debug   bool           `env:"INJECT_TEST_DEBUG"`
+		Tags    []string       `env:"INJECT_TEST_TAGS"`
+		Limits  map[string]int `inject:"limits" env:"INJECT_TEST_LIMITS"`
+		Missing string         `inject:"missing" value:"default" env:"INJECT_TEST_MISSING"`
+	}
+
+	t.Setenv("INJECT_TEST_PORT", "9090")
+	t.Setenv("INJECT_TEST_HOST", "example.com")
+	t.Setenv("INJECT_TEST_DEBUG", "true")
+	t.Setenv("INJECT_TEST_TAGS", `["a", "b"]`)
+	t.Setenv("INJECT_TEST_LIMITS", `{"cpu": 2}`)
+
+	testObject, err := Instanciate[testStruct]()
+
+	if err != nil {
+		t.Fatalf("Error calling Instanciate: %v", err)
+	}
+
+	expected := testStruct{
+		Port:    9090,
+		Host:    "example.com",
+		Debug:   true,
+		Tags:    []string{"a", "b"},
+		Limits:  map[string]int{"cpu": 2},
+		Missing: "default",
+	}
+
+	if !reflect.DeepEqual(*testObject, expected) {
+		t.Fatalf("tested object env fields: injection failed.\ntestObject = %+v\nexpected   = %+v", *testObject, expected)
+	}
+
+	// Args take precedence over env variables
+	testObject, err = InstanciateWithArgs[testStruct](Args{"port": 7070}, true)
+
+	if err != nil {
+		t.Fatalf("Error calling InstanciateWithArgs: %v", err)
+	}
+
+	if testObject.Port != 7070 || testObject.Host != "example.com" {
+		t.Fatalf("tested object env fields with args: injection failed. testObject = %+v", *testObject)
+	}
+
+	t.Setenv("INJECT_TEST_PORT", "not a number")
+
+	if _, err := Instanciate[testStruct](); err == nil {
+		t.Fatalf("Instanciate with invalid env variable. Expected error, got nil")
+	}
+
+}
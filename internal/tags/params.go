@@ -0,0 +1,110 @@
+package tags
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Field is the name and tag of a struct field, which params are matched
+// against.
+type Field struct {
+	Name string
+	Tag  reflect.StructTag
+}
+
+// ParamsByField assigns config params to the indexes of fields, the struct
+// named typeName: lists by position, maps by key (see FieldIndexByKey) and
+// scalars to the first field. Unknown keys are reported as errors.
+func ParamsByField(fields []Field, typeName string, params any) (map[int]any, error) {
+	if params == nil {
+		return nil, nil
+	}
+	result := map[int]any{}
+	data := reflect.ValueOf(params)
+	switch data.Kind() {
+	case reflect.Array, reflect.Slice:
+		if data.Len() > len(fields) {
+			return nil, fmt.Errorf("%d params for %d fields of %s", data.Len(), len(fields), typeName)
+		}
+		for i := 0; i < data.Len(); i++ {
+			result[i] = data.Index(i).Interface()
+		}
+	case reflect.Map:
+		var unknown []string
+		iter := data.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			i, ok := FieldIndexByKey(fields, key)
+			if !ok {
+				unknown = append(unknown, key)
+				continue
+			}
+			result[i] = iter.Value().Interface()
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("%s has no field for params key(s) %s", typeName, strings.Join(unknown, ", "))
+		}
+	default:
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("params for %s, which has no fields", typeName)
+		}
+		result[0] = params
+	}
+	return result, nil
+}
+
+// FieldIndexByKey finds the field named by a params key. The key may be the
+// inject tag alias, the yaml or json tag name or the field name, which is
+// matched case-insensitively when nothing else matches.
+func FieldIndexByKey(fields []Field, key string) (int, bool) {
+	for _, name := range []func(Field) string{
+		func(f Field) string { return f.Name },
+		func(f Field) string { return tagName(f, "inject") },
+		func(f Field) string { return tagName(f, "yaml") },
+		func(f Field) string { return tagName(f, "json") },
+	} {
+		for i, f := range fields {
+			if name(f) == key {
+				return i, true
+			}
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f.Name, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// tagName returns the name part of a struct tag, before any options.
+func tagName(f Field, key string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// JSONValue turns the yaml maps of params into maps with string keys, which
+// JSON can encode.
+func JSONValue(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, elem := range v {
+			result[fmt.Sprint(key)] = JSONValue(elem)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, elem := range v {
+			result[i] = JSONValue(elem)
+		}
+		return result
+	}
+	return value
+}
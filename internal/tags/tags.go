@@ -0,0 +1,89 @@
+// Package tags parses the inject struct tag and matches config params to
+// fields. It is shared by the inject package and its tools so they all read
+// tags and params the same way.
+package tags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scopes of the instances resolved for struct and lazy fields.
+const (
+	ScopeTransient = "transient"
+	ScopeRequest   = "request"
+	ScopeSingleton = "singleton"
+)
+
+// Inject is the parsed inject tag of a field: an alias followed by comma
+// separated options, like inject:"printer,struct,name=primary,scope=request".
+//
+// The struct option resolves the field through the config bindings; a tag
+// made only of the struct alias means the same. A field whose type the
+// config doesn't bind is an error, unless it's optional: it's then left
+// unset, while other errors are still reported. Lazy fields are a func() T or
+// func() (T, error) resolving the binding on each call. Private allows setting
+// an unexported field. Name selects the interfaces entry with the same named
+// key, and scope is one of transient, request or singleton.
+type Inject struct {
+	Alias    string
+	Struct   bool
+	Optional bool
+	Lazy     bool
+	Private  bool
+	Name     string
+	Scope    string
+}
+
+// ParseInject parses the value of an inject tag. On error the options parsed
+// so far are returned along with it.
+func ParseInject(value string) (Inject, error) {
+	parts := strings.Split(value, ",")
+	tag := Inject{Alias: strings.TrimSpace(parts[0])}
+	if tag.Alias == "struct" {
+		tag.Alias = ""
+		tag.Struct = true
+	}
+
+	for _, option := range parts[1:] {
+		option = strings.TrimSpace(option)
+		key, value, hasValue := strings.Cut(option, "=")
+		if hasValue && value == "" {
+			return tag, fmt.Errorf("inject tag: option %q has no value", key)
+		}
+		switch {
+		case option == "":
+		case option == "struct":
+			tag.Struct = true
+		case option == "optional":
+			tag.Optional = true
+		case option == "lazy":
+			tag.Lazy = true
+		case option == "private":
+			tag.Private = true
+		case key == "name" && hasValue:
+			tag.Name = value
+		case key == "scope" && hasValue:
+			if value != ScopeTransient && value != ScopeRequest && value != ScopeSingleton {
+				return tag, fmt.Errorf("inject tag: unknown scope %q, use transient, request or singleton", value)
+			}
+			tag.Scope = value
+		default:
+			return tag, fmt.Errorf("inject tag: unknown option %q", option)
+		}
+	}
+	return tag, nil
+}
+
+// Resolves reports whether the field is resolved through the config bindings.
+func (tag Inject) Resolves() bool {
+	return tag.Struct || tag.Lazy
+}
+
+// Check reports the options that only make sense together.
+func (tag Inject) Check() error {
+	if (tag.Optional || tag.Name != "" || tag.Scope != "") && !tag.Resolves() {
+		return fmt.Errorf("inject tag: optional, name and scope need the struct or lazy option")
+	}
+	return nil
+}
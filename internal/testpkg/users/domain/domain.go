@@ -0,0 +1,15 @@
+// Package domain is a test fixture sharing its short name with
+// internal/testpkg/billing/domain.
+package domain
+
+type Repository interface {
+	Owner() string
+}
+
+type SQLRepository struct {
+	Table string
+}
+
+func (r *SQLRepository) Owner() string {
+	return "users:" + r.Table
+}
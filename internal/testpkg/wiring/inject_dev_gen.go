@@ -0,0 +1,98 @@
+// Code generated by injectgen from wiring.dev.yaml; DO NOT EDIT.
+
+package wiring
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/carlosranoya/inject"
+)
+
+// NewServiceDev builds a Service like inject.Instanciate[Service]().
+func NewServiceDev() (*Service, error) {
+	v, err := buildServiceDev()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewConsolePrinterDev builds a ConsolePrinter like inject.Instanciate[ConsolePrinter]().
+func NewConsolePrinterDev() (*ConsolePrinter, error) {
+	v, err := buildConsolePrinterDev()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewPrinterDev builds the Printer bound by the config like inject.InstaciateInjected[Printer]().
+func NewPrinterDev() (Printer, error) {
+	v, err := bindConsolePrinterDev()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func buildServiceDev() (*Service, error) {
+	v := &Service{}
+	v.Name = "service"
+	if text, ok := os.LookupEnv("WIRING_SERVICE_NAME"); ok {
+		v.Name = text
+	}
+	v.Debug = false
+	x1, err := bindConsolePrinterDev()
+	if err != nil {
+		return nil, fmt.Errorf("field Printer: %w", err)
+	}
+	v.Printer = x1
+	x2, err := buildLimitsDev()
+	if err != nil {
+		return nil, fmt.Errorf("field Limits: %w", err)
+	}
+	v.Limits = *x2
+	x3, err := buildLimitsDev()
+	if err != nil {
+		return nil, fmt.Errorf("field Backup: %w", err)
+	}
+	v.Backup = x3
+	v.retries = 3
+	return v, nil
+}
+
+func buildConsolePrinterDev() (*ConsolePrinter, error) {
+	v := &ConsolePrinter{}
+	v.Prefix = "> "
+	v.Width = 80
+	return v, nil
+}
+
+func bindConsolePrinterDev() (*ConsolePrinter, error) {
+	v := &ConsolePrinter{}
+	v.Prefix = "dev> "
+	v.Width = 120
+	return v, nil
+}
+
+func buildLimitsDev() (*Limits, error) {
+	v := &Limits{}
+	v.MaxConns = 10
+	v.Ratio = 0.5
+	x4, err := inject.ParseText[[]string]("[\"a\", \"b\"]")
+	if err != nil {
+		return nil, fmt.Errorf("field Hosts: %w", err)
+	}
+	v.Hosts = x4
+	return v, nil
+}
@@ -0,0 +1,152 @@
+// Code generated by injectgen from wiring.prod.yaml; DO NOT EDIT.
+
+package wiring
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carlosranoya/inject"
+)
+
+// NewServiceProd builds a Service like inject.Instanciate[Service]().
+func NewServiceProd() (*Service, error) {
+	v, err := buildServiceProd()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewConsolePrinterProd builds a ConsolePrinter like inject.Instanciate[ConsolePrinter]().
+func NewConsolePrinterProd() (*ConsolePrinter, error) {
+	v, err := buildConsolePrinterProd()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewFilePrinterProd builds a FilePrinter like inject.Instanciate[FilePrinter]().
+func NewFilePrinterProd() (*FilePrinter, error) {
+	v, err := buildFilePrinterProd()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewPrinterProd builds the Printer bound by the config like inject.InstaciateInjected[Printer]().
+func NewPrinterProd() (Printer, error) {
+	v, err := bindFilePrinterProd()
+	if err != nil {
+		return nil, err
+	}
+	if err := inject.ValidateStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func buildServiceProd() (*Service, error) {
+	v := &Service{}
+	v.Name = "service"
+	if text, ok := os.LookupEnv("WIRING_SERVICE_NAME"); ok {
+		v.Name = text
+	}
+	v.Debug = false
+	x1, err := bindFilePrinterProd()
+	if err != nil {
+		return nil, fmt.Errorf("field Printer: %w", err)
+	}
+	v.Printer = x1
+	x2, err := buildConsolePrinterProd()
+	if err != nil {
+		return nil, fmt.Errorf("field Audit: %w", err)
+	}
+	v.Audit = x2
+	x3, err := buildLimitsProd()
+	if err != nil {
+		return nil, fmt.Errorf("field Limits: %w", err)
+	}
+	v.Limits = *x3
+	x4, err := buildLimitsProd()
+	if err != nil {
+		return nil, fmt.Errorf("field Backup: %w", err)
+	}
+	v.Backup = x4
+	v.retries = 3
+	return v, nil
+}
+
+func buildConsolePrinterProd() (*ConsolePrinter, error) {
+	v := &ConsolePrinter{}
+	v.Prefix = "> "
+	v.Width = 80
+	return v, nil
+}
+
+func buildFilePrinterProd() (*FilePrinter, error) {
+	v := &FilePrinter{}
+	v.Path = "/tmp/service.log"
+	x5, err := inject.ParseText[time.Duration]("5s")
+	if err != nil {
+		return nil, fmt.Errorf("field Timeout: %w", err)
+	}
+	v.Timeout = x5
+	v.Mode = 420
+	if text, ok := os.LookupEnv("WIRING_FILE_MODE"); ok {
+		x6, err := inject.ParseText[uint32](text)
+		if err != nil {
+			return nil, fmt.Errorf("field Mode: env WIRING_FILE_MODE: %w", err)
+		}
+		v.Mode = x6
+	}
+	return v, nil
+}
+
+func bindFilePrinterProd() (*FilePrinter, error) {
+	v := &FilePrinter{}
+	v.Path = "/var/log/service.log"
+	x7, err := inject.ParseText[time.Duration]("5s")
+	if err != nil {
+		return nil, fmt.Errorf("field Timeout: %w", err)
+	}
+	v.Timeout = x7
+	x8, err := inject.ParseText[time.Duration]("30s")
+	if err != nil {
+		return nil, fmt.Errorf("field Timeout: %w", err)
+	}
+	v.Timeout = x8
+	v.Mode = 420
+	if text, ok := os.LookupEnv("WIRING_FILE_MODE"); ok {
+		x9, err := inject.ParseText[uint32](text)
+		if err != nil {
+			return nil, fmt.Errorf("field Mode: env WIRING_FILE_MODE: %w", err)
+		}
+		v.Mode = x9
+	}
+	return v, nil
+}
+
+func buildLimitsProd() (*Limits, error) {
+	v := &Limits{}
+	v.MaxConns = 10
+	v.Ratio = 0.5
+	x10, err := inject.ParseText[[]string]("[\"a\", \"b\"]")
+	if err != nil {
+		return nil, fmt.Errorf("field Hosts: %w", err)
+	}
+	v.Hosts = x10
+	return v, nil
+}
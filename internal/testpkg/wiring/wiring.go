@@ -0,0 +1,51 @@
+// Package wiring declares the types wired by the code generated by injectgen,
+// which is compared with the inject package in its tests.
+package wiring
+
+import "time"
+
+//go:generate go run ../../../cmd/injectgen -config wiring.dev.yaml
+//go:generate go run ../../../cmd/injectgen -config wiring.prod.yaml
+
+type Printer interface {
+	Print(message string) string
+}
+
+type ConsolePrinter struct {
+	Prefix string `inject:"prefix" value:"> "`
+	Width  int    `inject:"width" value:"80"`
+}
+
+func (p *ConsolePrinter) Print(message string) string {
+	return p.Prefix + message
+}
+
+type FilePrinter struct {
+	Path    string        `inject:"path" value:"/tmp/service.log"`
+	Timeout time.Duration `inject:"timeout" value:"5s"`
+	Mode    uint32        `inject:"mode" value:"420" env:"WIRING_FILE_MODE"`
+}
+
+func (p *FilePrinter) Print(message string) string {
+	return p.Path + ": " + message
+}
+
+type Limits struct {
+	MaxConns int      `inject:"maxConns" value:"10" validate:"min=1"`
+	Ratio    float64  `inject:"ratio" value:"0.5"`
+	Hosts    []string `inject:"hosts" value:"[\"a\", \"b\"]"`
+}
+
+type Service struct {
+	Name    string  `inject:"name" value:"service" env:"WIRING_SERVICE_NAME"`
+	Debug   bool    `inject:"debug" value:"false"`
+	Printer Printer `inject:"struct"`
+	Audit   Printer `inject:"audit,struct,optional,name=audit"`
+	Limits  Limits  `inject:"limits"`
+	Backup  *Limits `inject:"backup"`
+	retries int     `inject:"retries,private" value:"3"`
+}
+
+func (s *Service) Retries() int {
+	return s.retries
+}
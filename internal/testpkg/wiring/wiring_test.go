@@ -0,0 +1,74 @@
+package wiring
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/carlosranoya/inject"
+)
+
+func loadProfile(t *testing.T, file string) {
+	inject.ResetData()
+	inject.AddInterface[Printer]()
+	inject.AddInjectable[Service]()
+	inject.AddInjectable[ConsolePrinter]()
+	inject.AddInjectable[FilePrinter]()
+	if err := inject.LoadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeneratedWiring(t *testing.T) {
+
+	t.Setenv("WIRING_FILE_MODE", "384")
+
+	profiles := []struct {
+		file       string
+		newService func() (*Service, error)
+		newPrinter func() (Printer, error)
+	}{
+		{"wiring.dev.yaml", NewServiceDev, NewPrinterDev},
+		{"wiring.prod.yaml", NewServiceProd, NewPrinterProd},
+	}
+
+	for _, profile := range profiles {
+		loadProfile(t, profile.file)
+
+		expected, err := inject.Instanciate[Service]()
+		if err != nil {
+			t.Fatalf("%s: Instanciate(). Expected no error, got %v", profile.file, err)
+		}
+		generated, err := profile.newService()
+		if err != nil {
+			t.Fatalf("%s: NewService(). Expected no error, got %v", profile.file, err)
+		}
+		if !reflect.DeepEqual(generated, expected) {
+			t.Fatalf("%s: NewService(). Expected %+v, got %+v", profile.file, expected, generated)
+		}
+
+		expectedPrinter, err := inject.InstaciateInjected[Printer]()
+		if err != nil {
+			t.Fatalf("%s: InstaciateInjected(). Expected no error, got %v", profile.file, err)
+		}
+		generatedPrinter, err := profile.newPrinter()
+		if err != nil {
+			t.Fatalf("%s: NewPrinter(). Expected no error, got %v", profile.file, err)
+		}
+		if !reflect.DeepEqual(generatedPrinter, expectedPrinter) {
+			t.Fatalf("%s: NewPrinter(). Expected %+v, got %+v", profile.file, expectedPrinter, generatedPrinter)
+		}
+	}
+}
+
+func TestGeneratedWiringEnvError(t *testing.T) {
+
+	t.Setenv("WIRING_FILE_MODE", "rw")
+
+	loadProfile(t, "wiring.prod.yaml")
+	if _, err := inject.Instanciate[Service](); err == nil {
+		t.Fatal("Instanciate(). Expected an error for an invalid env variable")
+	}
+	if _, err := NewServiceProd(); err == nil {
+		t.Fatal("NewServiceProd(). Expected an error for an invalid env variable")
+	}
+}
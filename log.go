@@ -0,0 +1,42 @@
+package inject
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+)
+
+// discardHandler drops every record, keeping the package silent by default.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// currentLogger is set by SetLogger while injections may be running.
+var currentLogger atomic.Pointer[slog.Logger]
+
+// logger returns the logger set by SetLogger, or one discarding every record.
+func logger() *slog.Logger {
+	if l := currentLogger.Load(); l != nil {
+		return l
+	}
+	return discardLogger
+}
+
+// SetLogger sets the logger recording, at debug level, the registrations,
+// config imports, binding decisions and the fields set by each injection.
+// The package logs nothing until a logger is set; nil silences it again.
+//
+//	inject.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+func SetLogger(l *slog.Logger) {
+	currentLogger.Store(l)
+}
+
+func logRegistration(kind string, t reflect.Type) {
+	logger().Debug("inject: registered", slog.String("kind", kind), slog.String("type", t.String()))
+}
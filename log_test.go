@@ -0,0 +1,63 @@
+package inject
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+
+	var out bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	init_instances()
+	ImportConfig("test_files/injection-config.local.yaml")
+	if _, err := Instanciate[overriddenService](); err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		`msg="inject: registered" kind=interface type=inject.iMessagePrinter`,
+		`msg="inject: config loaded" file=test_files/injection-config.local.yaml`,
+		`msg="inject: binding" type=inject.iMessagePrinter name="" interface=inject.iMessagePrinter injectable=inject.messagePrinterA`,
+		`msg="inject: field set" field=Printer source=binding`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("SetLogger(). Expected %s in the log, got:\n%s", expected, out.String())
+		}
+	}
+
+	SetLogger(nil)
+	out.Reset()
+	if _, err := Instanciate[overriddenService](); err != nil || out.Len() > 0 {
+		t.Fatalf("SetLogger(nil). Expected no log, got %q, %v", out.String(), err)
+	}
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+
+	init_instances()
+	ImportConfig("test_files/injection-config.local.yaml")
+	defer SetLogger(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := Instanciate[overriddenService](); err != nil {
+				t.Errorf("Instanciate(). Expected no error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			SetLogger(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		}()
+	}
+	wg.Wait()
+}
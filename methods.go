@@ -0,0 +1,96 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var injectionMethods map[reflect.Type][]string = make(map[reflect.Type][]string)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// AddInjectionMethods registers methods of T to be called after its fields
+// are injected, like SetLogger(l Logger). Each argument is resolved like an
+// inject:"struct" field, or taken from a factory. Without names, every method
+// whose name starts with Inject is called, in alphabetical order.
+// A method may return an error, which is returned by the injection.
+func AddInjectionMethods[T any](methods ...string) {
+	var t T
+	stateMu.Lock()
+	injectionMethods[reflect.TypeOf(t)] = methods
+	stateMu.Unlock()
+}
+
+// callInjectionMethods calls the injection methods registered for the type of
+// v, which must be an addressable struct.
+func (in *injection) callInjectionMethods(v reflect.Value) error {
+	stateMu.Lock()
+	names, ok := injectionMethods[v.Type()]
+	stateMu.Unlock()
+	if !ok {
+		return nil
+	}
+	p := v.Addr()
+
+	if len(names) == 0 {
+		for i := 0; i < p.NumMethod(); i++ {
+			if name := p.Type().Method(i).Name; strings.HasPrefix(name, "Inject") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		method := p.MethodByName(name)
+		if !method.IsValid() {
+			return fmt.Errorf("%v has no method %s", p.Type(), name)
+		}
+		mt := method.Type()
+		args := make([]reflect.Value, mt.NumIn())
+		for i := range args {
+			arg, err := resolveArgument(mt.In(i), in.child(name))
+			if err != nil {
+				return fmt.Errorf("method %s: argument %d: %w", name, i, err)
+			}
+			args[i] = arg
+		}
+		results := method.Call(args)
+		if n := len(results); n > 0 && mt.Out(n-1) == errorType && !results[n-1].IsNil() {
+			return fmt.Errorf("method %s: %w", name, results[n-1].Interface().(error))
+		}
+	}
+	return nil
+}
+
+// resolveArgument resolves a method argument of type t from a registered
+// factory or from the config bindings.
+func resolveArgument(t reflect.Type, in *injection) (reflect.Value, error) {
+	elem := t
+	if t.Kind() == reflect.Pointer {
+		elem = t.Elem()
+	}
+	stateMu.Lock()
+	f, ok := factories[elem].(iInstanceFactory)
+	stateMu.Unlock()
+	if ok {
+		if instance := f.instanceValue(); instance.IsValid() {
+			if t.Kind() == reflect.Pointer {
+				return instance, nil
+			}
+			return instance.Elem(), nil
+		}
+	}
+
+	instance, err := buildInjectable(t, "", in)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !instance.IsValid() {
+		return reflect.Value{}, fmt.Errorf("can't resolve %v, it's not bound by the config nor built by a factory", t)
+	}
+	if t.Kind() == reflect.Struct {
+		return instance.Elem(), nil
+	}
+	return instance, nil
+}
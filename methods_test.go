@@ -0,0 +1,70 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+)
+
+type methodService struct {
+	Name string `inject:"name" value:"\"service\""`
+
+	printer   iMessagePrinter
+	container *printerContainer
+	calls     []string
+}
+
+func (s *methodService) SetPrinter(p iMessagePrinter) {
+	s.printer = p
+	s.calls = append(s.calls, "SetPrinter")
+}
+
+func (s *methodService) InjectContainer(c *printerContainer) {
+	s.container = c
+	s.calls = append(s.calls, "InjectContainer")
+}
+
+func (s *methodService) InjectName() error {
+	if s.Name == "" {
+		return errors.New("name not injected")
+	}
+	s.calls = append(s.calls, "InjectName")
+	return nil
+}
+
+func TestInjectionMethods(t *testing.T) {
+
+	init_instances()
+	ImportConfig("test_files/injection-config.local.yaml")
+
+	AddInjectionMethods[methodService]("SetPrinter")
+
+	s, err := Instanciate[methodService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+	if _, ok := s.printer.(*messagePrinterA); !ok {
+		t.Fatalf("SetPrinter(). Expected *messagePrinterA, got %T", s.printer)
+	}
+	if s.container != nil || len(s.calls) != 1 {
+		t.Fatalf("Instanciate(). Expected only SetPrinter to be called, got %v", s.calls)
+	}
+
+	AddInjectionMethods[methodService]()
+
+	s, err = Instanciate[methodService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+	if s.container == nil || s.container.Printer == nil {
+		t.Fatalf("InjectContainer(). Expected the factory instance, got %v", s.container)
+	}
+	if len(s.calls) != 2 || s.calls[0] != "InjectContainer" || s.calls[1] != "InjectName" {
+		t.Fatalf("Instanciate(). Expected Inject methods in order, got %v", s.calls)
+	}
+
+	AddInjectionMethods[methodService]("SetLogger")
+
+	if _, err := Instanciate[methodService](); err == nil {
+		t.Fatal("Instanciate(). Expected an error for a missing method")
+	}
+}
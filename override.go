@@ -0,0 +1,65 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// TB is the part of testing.TB used by Override.
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Setenv(key, value string)
+	Fatal(args ...any)
+}
+
+var overrides map[reflect.Type]reflect.Value = make(map[reflect.Type]reflect.Value)
+
+// Override makes every resolution of I, by InstaciateInjected, inject:"struct"
+// and lazy fields or injection methods, return fake instead of the binding of
+// the config, until the end of the test:
+//
+//	inject.Override[Repository](t, &fakeRepository{})
+//
+// The registry and config are left untouched, and the previous binding is
+// restored by t.Cleanup. Overrides are global while they last, so Override
+// fails a test marked with t.Parallel, and the test can't be marked after it.
+func Override[I any](t TB, fake I) {
+	t.Helper()
+	key := reflect.TypeOf((*I)(nil)).Elem()
+	markSerial(t, key)
+
+	stateMu.Lock()
+	previous, overridden := overrides[key]
+	overrides[key] = reflect.ValueOf(&fake).Elem()
+	stateMu.Unlock()
+
+	t.Cleanup(func() {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		if overridden {
+			overrides[key] = previous
+		} else {
+			delete(overrides, key)
+		}
+	})
+}
+
+// markSerial relies on t.Setenv, which panics in parallel tests and keeps
+// the test from calling t.Parallel afterwards.
+func markSerial(t TB, key reflect.Type) {
+	t.Helper()
+	defer func() {
+		if recover() != nil {
+			t.Fatal("inject.Override can't be used in parallel tests, it changes the bindings of every test")
+		}
+	}()
+	t.Setenv("INJECT_OVERRIDE", key.String())
+}
+
+// overridden returns the fake set by Override for t.
+func overridden(t reflect.Type) (reflect.Value, bool) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	fake, ok := overrides[t]
+	return fake, ok
+}
@@ -0,0 +1,135 @@
+package inject
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakePrinter struct {
+	message string
+}
+
+func (p *fakePrinter) Print() {}
+func (p *fakePrinter) GetMessage() string {
+	return p.message
+}
+
+type overriddenService struct {
+	Printer iMessagePrinter        `inject:"struct"`
+	Lazy    func() iMessagePrinter `inject:"lazy,lazy"`
+}
+
+func TestOverride(t *testing.T) {
+
+	init_instances()
+	ImportConfig("test_files/injection-config.local.yaml")
+
+	fake := &fakePrinter{message: "fake"}
+
+	t.Run("override", func(t *testing.T) {
+		Override[iMessagePrinter](t, fake)
+
+		printer, err := InstaciateInjected[iMessagePrinter]()
+		if err != nil || printer != fake {
+			t.Fatalf("InstaciateInjected(). Expected the fake, got %v, %v", printer, err)
+		}
+
+		s, err := Instanciate[overriddenService]()
+		if err != nil {
+			t.Fatalf("Instanciate(). Expected no error, got %v", err)
+		}
+		if s.Printer != fake || s.Lazy() != fake {
+			t.Fatalf("Instanciate(). Expected the fake in every field, got %v and %v", s.Printer, s.Lazy())
+		}
+
+		t.Run("nested", func(t *testing.T) {
+			other := &fakePrinter{message: "other"}
+			Override[iMessagePrinter](t, other)
+			if printer, _ := InstaciateInjected[iMessagePrinter](); printer != other {
+				t.Fatalf("InstaciateInjected(). Expected the nested fake, got %v", printer)
+			}
+		})
+
+		if printer, _ := InstaciateInjected[iMessagePrinter](); printer != fake {
+			t.Fatalf("InstaciateInjected(). Expected the fake to be restored, got %v", printer)
+		}
+	})
+
+	printer, err := InstaciateInjected[iMessagePrinter]()
+	if err != nil {
+		t.Fatalf("InstaciateInjected(). Expected no error, got %v", err)
+	}
+	if _, ok := printer.(*messagePrinterA); !ok {
+		t.Fatalf("InstaciateInjected(). Expected the config binding after the test, got %T", printer)
+	}
+}
+
+type notAPrinter struct{}
+
+func TestInstaciateInjectedMismatch(t *testing.T) {
+
+	init_instances()
+	defer ResetData()
+	AddInjectable[notAPrinter]()
+	config = Config{
+		Injectables: []InjectableDescription{{ComponentPath: ComponentPath{Name: "notAPrinter", Package: "inject"}}},
+		Interfaces:  []InterfaceDescription{{ComponentPath: ComponentPath{Name: "iMessagePrinter", Package: "inject"}, Injectable: "notAPrinter"}},
+	}
+
+	if printer, err := InstaciateInjected[iMessagePrinter](); err == nil || printer != nil {
+		t.Fatalf("InstaciateInjected(). Expected an error for an injectable not implementing the interface, got %v, %v", printer, err)
+	}
+
+	if s, err := Instanciate[decoratedService](); err == nil {
+		t.Fatalf("Instanciate(). Expected an error for the struct field, got %+v", s)
+	}
+
+	type lazyService struct {
+		Printer func() (iMessagePrinter, error) `inject:"printer,lazy"`
+	}
+	s, err := Instanciate[lazyService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error before the lazy field is called, got %v", err)
+	}
+	if printer, err := s.Printer(); err == nil || printer != nil {
+		t.Fatalf("Printer(). Expected an error for the lazy field, got %v, %v", printer, err)
+	}
+
+	t.Run("decorated", func(t *testing.T) {
+		Decorate(prefixWith("decorated: "))
+		defer func() { decorators = map[reflect.Type][]decorator{} }()
+		if printer, err := InstaciateInjected[iMessagePrinter](); err == nil || printer != nil {
+			t.Fatalf("InstaciateInjected(). Expected an error from the decorator, got %v, %v", printer, err)
+		}
+	})
+
+	t.Run("nil fake", func(t *testing.T) {
+		Override[iMessagePrinter](t, nil)
+		if printer, err := InstaciateInjected[iMessagePrinter](); err != nil || printer != nil {
+			t.Fatalf("InstaciateInjected(). Expected the nil fake, got %v, %v", printer, err)
+		}
+	})
+}
+
+// parallelTB reports t.Setenv calls the way a test marked with t.Parallel does.
+type parallelTB struct {
+	*testing.T
+	failed bool
+}
+
+func (p *parallelTB) Setenv(key, value string) {
+	panic("testing: test using t.Setenv can not use t.Parallel")
+}
+
+func (p *parallelTB) Fatal(args ...any) {
+	p.failed = true
+}
+
+func TestOverrideParallel(t *testing.T) {
+
+	tb := &parallelTB{T: t}
+	Override[iMessagePrinter](tb, &fakePrinter{})
+	if !tb.failed {
+		t.Fatal("Override(). Expected a parallel test to fail")
+	}
+}
@@ -0,0 +1,226 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a violation of a validate tag rule.
+type FieldError struct {
+	Field string // path of the field, like DB.Port or Hosts[0]
+	Rule  string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s: %s", e.Field, e.Msg)
+}
+
+// validateFields checks the validate tags of a struct, its nested structs and
+// the structs in its slices, arrays and maps. Rules are comma separated:
+//
+//	required      the field must not be zero (or empty, for collections)
+//	omitempty     skip the remaining rules when the field is zero
+//	min=N, max=N  bounds for numbers, or for the length of strings and collections
+//	oneof=a b c   the value must be one of the space separated options
+//
+// Only the values that can hold validate tags are walked: fields whose type
+// nests them and the values set through inject tags, like bound interfaces.
+func validateFields(v reflect.Value) error {
+	var errs ValidationErrors
+	validateStruct(v, "", map[visit]bool{}, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStruct(v reflect.Value, prefix string, visited map[visit]bool, errs *ValidationErrors) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Pointer && !visitOnce(v, visited) {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			path := f.Name
+			if prefix != "" {
+				path = prefix + "." + f.Name
+			}
+			if rules := f.Tag.Get("validate"); rules != "" {
+				*errs = append(*errs, checkRules(v.Field(i), path, rules)...)
+			}
+			if f.Tag.Get("inject") != "" || hasRules(f.Type) {
+				validateStruct(v.Field(i), path, visited, errs)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !hasRules(v.Type().Elem()) || v.Kind() == reflect.Slice && !visitOnce(v, visited) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateStruct(v.Index(i), fmt.Sprintf("%s[%d]", prefix, i), visited, errs)
+		}
+	case reflect.Map:
+		if !hasRules(v.Type().Elem()) || !visitOnce(v, visited) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			validateStruct(iter.Value(), fmt.Sprintf("%s[%v]", prefix, formatValue(iter.Key())), visited, errs)
+		}
+	}
+}
+
+// visitOnce records a pointer, slice or map, reporting false when it was
+// already walked.
+func visitOnce(v reflect.Value, visited map[visit]bool) bool {
+	key := visit{v.Pointer(), v.Type()}
+	if visited[key] {
+		return false
+	}
+	visited[key] = true
+	return true
+}
+
+var ruleTypes = map[reflect.Type]bool{}
+
+// hasRules reports whether values of type t can hold validate tags: structs
+// with validate or inject tagged fields, and the pointers, collections and
+// structs nesting them. Results are cached by type.
+func hasRules(t reflect.Type) bool {
+	stateMu.Lock()
+	found, ok := ruleTypes[t]
+	stateMu.Unlock()
+	if ok {
+		return found
+	}
+	found = reachesRules(t, map[reflect.Type]bool{})
+	stateMu.Lock()
+	ruleTypes[t] = found
+	stateMu.Unlock()
+	return found
+}
+
+func reachesRules(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return reachesRules(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Tag.Get("validate") != "" || f.Tag.Get("inject") != "" || reachesRules(f.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkRules(v reflect.Value, path string, rules string) []error {
+	var errs []error
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		name, arg, _ := strings.Cut(rule, "=")
+		fail := func(format string, a ...any) {
+			errs = append(errs, &FieldError{Field: path, Rule: name, Msg: fmt.Sprintf(format, a...)})
+		}
+
+		switch name {
+		case "":
+		case "required":
+			if isEmptyValue(v) {
+				fail("is required")
+			}
+		case "omitempty":
+			if isEmptyValue(v) {
+				return errs
+			}
+		case "min", "max":
+			bound, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				fail("invalid rule %q", rule)
+				continue
+			}
+			n, what, ok := measure(v)
+			if !ok {
+				fail("rule %s is not supported for %v", name, v.Type())
+				continue
+			}
+			if name == "min" && n < bound {
+				fail("%s must be at least %s, got %v", what, arg, n)
+			}
+			if name == "max" && n > bound {
+				fail("%s must be at most %s, got %v", what, arg, n)
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			value := formatValue(v)
+			found := false
+			for _, option := range options {
+				if option == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				fail("must be one of [%s], got %q", strings.Join(options, " "), value)
+			}
+		default:
+			fail("unknown validate rule %q", name)
+		}
+	}
+	return errs
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// measure returns the number compared by min and max: the value of numbers
+// and the length of strings and collections.
+func measure(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), "length", true
+	}
+	if isNumberKind(v.Kind()) {
+		n, _ := numberAsFloat(v)
+		return n, "value", true
+	}
+	return 0, "", false
+}
+
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String()
+	case isIntKind(v.Kind()):
+		return strconv.FormatInt(v.Int(), 10)
+	case isUintKind(v.Kind()):
+		return strconv.FormatUint(v.Uint(), 10)
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return v.Type().String()
+}
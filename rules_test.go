@@ -0,0 +1,119 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateRules(t *testing.T) {
+
+	type database struct {
+		Host string `inject:"host" validate:"required"`
+		Port int    `inject:"port" value:"5432" validate:"min=1,max=65535"`
+	}
+
+	type server struct {
+		Port     int               `inject:"port" value:"8080" validate:"required,min=1,max=65535"`
+		LogLevel string            `inject:"logLevel" value:"info" validate:"oneof=debug info warn"`
+		Name     string            `inject:"name" validate:"omitempty,min=3"`
+		Hosts    []string          `inject:"hosts" value:"[\"a\"]" validate:"min=1"`
+		DB       *database         `inject:"db"`
+		Replicas []database        `inject:"replicas"`
+		Labels   map[string]string `inject:"labels" validate:"max=2"`
+	}
+
+	_, err := Instanciate[server]()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Instanciate(). Expected one violation, got %v", err)
+	}
+
+	var fieldError *FieldError
+	if !errors.As(errs[0], &fieldError) || fieldError.Field != "DB.Host" || fieldError.Rule != "required" {
+		t.Fatalf("Instanciate(). Expected DB.Host to be required, got %v", errs[0])
+	}
+
+	args := Args{
+		"port":     70000,
+		"logLevel": "trace",
+		"name":     "ab",
+		"hosts":    []any{},
+		"db":       map[string]any{"host": "db", "port": 0},
+		"replicas": []any{map[string]any{"port": 1}},
+		"labels":   map[string]any{"a": "1", "b": "2", "c": "3"},
+	}
+
+	_, err = InstanciateWithArgs[server](args, true)
+
+	expected := []string{"Port", "LogLevel", "Name", "Hosts", "DB.Port", "Replicas[0].Host", "Labels"}
+	if !errors.As(err, &errs) || len(errs) != len(expected) {
+		t.Fatalf("InstanciateWithArgs(). Expected %d violations, got %v", len(expected), err)
+	}
+	for i, field := range expected {
+		if !errors.As(errs[i], &fieldError) || fieldError.Field != field {
+			t.Fatalf("InstanciateWithArgs(). Expected violation on %s, got %v", field, errs[i])
+		}
+	}
+
+	t.Log(err)
+
+	args = Args{"db": map[string]any{"host": "db", "port": 5432}, "name": ""}
+
+	if _, err := InstanciateWithArgs[server](args, true); err != nil {
+		t.Fatalf("InstanciateWithArgs(). Expected no violations, got %v", err)
+	}
+
+	type unknownRule struct {
+		Value int `inject:"value" validate:"positive"`
+	}
+
+	if _, err := Instanciate[unknownRule](); err == nil {
+		t.Fatalf("Instanciate() with unknown rule. Expected error, got nil")
+	}
+
+}
+
+type ruleEntry struct {
+	Port int `validate:"min=1"`
+	Next ruleMap
+}
+
+type ruleMap map[string]ruleEntry
+
+type ruleItem struct {
+	Port int `validate:"min=1"`
+	Next []ruleItem
+}
+
+func TestValidateWalk(t *testing.T) {
+
+	m := ruleMap{}
+	m["self"] = ruleEntry{Port: 0, Next: m}
+	err := ValidateStruct(&struct{ Entries ruleMap }{m})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("ValidateStruct() with a map containing itself. Expected one violation, got %v", err)
+	}
+
+	items := make([]ruleItem, 1)
+	items[0] = ruleItem{Port: 0, Next: items}
+	err = ValidateStruct(&struct{ Items []ruleItem }{items})
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("ValidateStruct() with a slice containing itself. Expected one violation, got %v", err)
+	}
+
+	// values not set through inject tags are opaque
+	type holder struct {
+		Opaque any
+		Bound  any `inject:"bound"`
+	}
+	err = ValidateStruct(&holder{Opaque: ruleItem{}, Bound: ruleItem{Port: 1}})
+	if err != nil {
+		t.Fatalf("ValidateStruct(). Expected the opaque field to be skipped, got %v", err)
+	}
+	err = ValidateStruct(&holder{Bound: ruleItem{}})
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].(*FieldError).Field != "Bound.Port" {
+		t.Fatalf("ValidateStruct(). Expected a violation on Bound.Port, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// RegistrySnapshot is a copy of the registry, config and factory state taken
+// by Snapshot. It can't be modified, and can be restored any number of times.
+type RegistrySnapshot struct {
+	factories        map[reflect.Type]iResetable
+	interfaces       map[string]reflect.Type
+	injectables      map[string]reflect.Type
+	injectionMethods map[reflect.Type][]string
+	converters       map[reflect.Type]converter
+	singletons       map[scopeKey]reflect.Value
+	decorators       map[reflect.Type][]decorator
+	namedDecorators  map[reflect.Type]map[string]decorator
+	config           Config
+	configFile       string
+	strictConfig     bool
+}
+
+// Snapshot copies the registered interfaces, injectables, factories (with
+// their singleton instances), injection methods, decorators and converters,
+// and the imported config, so tests and tools can undo their changes with
+// Restore:
+//
+//	snapshot := inject.Snapshot()
+//	defer inject.Restore(snapshot)
+//
+// Overrides are not part of the snapshot, they are undone by the end of their
+// test.
+func Snapshot() *RegistrySnapshot {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return &RegistrySnapshot{
+		factories:        cloneFactories(factories),
+		interfaces:       cloneMap(interfaces),
+		injectables:      cloneMap(injectables),
+		injectionMethods: cloneMap(injectionMethods),
+		converters:       cloneMap(converters),
+		singletons:       cloneMap(singletons),
+		decorators:       cloneMap(decorators),
+		namedDecorators:  cloneNamedDecorators(namedDecorators),
+		config:           cloneConfig(config),
+		configFile:       configFile,
+		strictConfig:     strictConfig,
+	}
+}
+
+// Restore replaces the registry, config and factory state with a snapshot.
+// The state is process-wide: Restore is safe to call while other goroutines
+// resolve, but it changes the bindings they see, so it gives no isolation
+// between tests running in parallel.
+func Restore(snapshot *RegistrySnapshot) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	factories = cloneFactories(snapshot.factories)
+	interfaces = cloneMap(snapshot.interfaces)
+	injectables = cloneMap(snapshot.injectables)
+	injectionMethods = cloneMap(snapshot.injectionMethods)
+	converters = cloneMap(snapshot.converters)
+	singletons = cloneMap(snapshot.singletons)
+	decorators = cloneMap(snapshot.decorators)
+	namedDecorators = cloneNamedDecorators(snapshot.namedDecorators)
+	config = cloneConfig(snapshot.config)
+	configFile = snapshot.configFile
+	strictConfig = snapshot.strictConfig
+}
+
+// injectableType, interfaceType and factoryType resolve a path like
+// getInjectableType and the others do, in the snapshot.
+func (s *RegistrySnapshot) injectableType(path ComponentPath) (reflect.Type, error) {
+	return path.resolve(registeredTypes(s.injectables))
+}
+
+func (s *RegistrySnapshot) interfaceType(path ComponentPath) (reflect.Type, error) {
+	return path.resolve(registeredTypes(s.interfaces))
+}
+
+func (s *RegistrySnapshot) factoryType(path ComponentPath) (reflect.Type, error) {
+	return path.resolve(factoryTypes(s.factories))
+}
+
+// findBinding looks t up in the config of the snapshot, like findBinding.
+func (s *RegistrySnapshot) findBinding(t reflect.Type, name string) (binding, error) {
+	return s.config.findBinding(t, name, registeredTypes(s.interfaces), registeredTypes(s.injectables))
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	result := make(map[K]V, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func cloneNamedDecorators(m map[reflect.Type]map[string]decorator) map[reflect.Type]map[string]decorator {
+	result := make(map[reflect.Type]map[string]decorator, len(m))
+	for t, named := range m {
+		result[t] = cloneMap(named)
+	}
+	return result
+}
+
+func cloneFactories(m map[reflect.Type]iResetable) map[reflect.Type]iResetable {
+	result := make(map[reflect.Type]iResetable, len(m))
+	for t, f := range m {
+		result[t] = f.clone()
+	}
+	return result
+}
+
+// cloneConfig copies the sections of a config. Params are shared, as they are
+// never modified once read.
+func cloneConfig(c Config) Config {
+	c.Factories = append([]FactoryDescription(nil), c.Factories...)
+	c.Injectables = append([]InjectableDescription(nil), c.Injectables...)
+	c.Interfaces = append([]InterfaceDescription(nil), c.Interfaces...)
+	return c
+}
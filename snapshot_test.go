@@ -0,0 +1,80 @@
+package inject
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+
+	init_instances()
+	AddFactory(&messagePrinterC{}, true)
+	ImportConfig("test_files/injection-config.local.yaml")
+
+	first := GetInstance[messagePrinterC](nil)
+	snapshot := Snapshot()
+
+	ResetData()
+	AddInjectable[TestStruct]()
+	ImportConfig("test_files/injection-config.prod.yaml")
+	SetStrictConfig(false)
+
+	if _, err := InstaciateInjected[iMessagePrinter](); err == nil {
+		t.Fatal("InstaciateInjected(). Expected an error after ResetData")
+	}
+
+	for i := 0; i < 2; i++ {
+		Restore(snapshot)
+
+		if !strictConfig {
+			t.Fatal("Restore(). Expected strict config to be restored")
+		}
+		if _, ok := injectables[typeKey(reflect.TypeOf(TestStruct{}))]; ok {
+			t.Fatal("Restore(). Expected TestStruct to be unregistered")
+		}
+		printer, err := InstaciateInjected[iMessagePrinter]()
+		if err != nil {
+			t.Fatalf("InstaciateInjected(). Expected no error, got %v", err)
+		}
+		if _, ok := printer.(*messagePrinterA); !ok {
+			t.Fatalf("InstaciateInjected(). Expected the local binding, got %T", printer)
+		}
+		if GetInstance[messagePrinterC](nil) != first {
+			t.Fatal("GetInstance(). Expected the singleton of the snapshot")
+		}
+
+		// changes after Restore don't reach the snapshot
+		config.Interfaces = nil
+		resetFactories()
+	}
+}
+
+func TestRestoreConcurrent(t *testing.T) {
+
+	ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := Snapshot()
+	defer Restore(snapshot)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := Instanciate[taggedService](); err != nil {
+				t.Errorf("Instanciate(). Expected no error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			Restore(Snapshot())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,119 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/carlosranoya/inject/internal/tags"
+)
+
+// Scopes of the instances resolved for struct and lazy fields.
+const (
+	ScopeTransient = tags.ScopeTransient // a new instance for every field (default)
+	ScopeRequest   = tags.ScopeRequest   // one instance per Instanciate or Inject call
+	ScopeSingleton = tags.ScopeSingleton // one instance until the config is reloaded
+)
+
+// injectTag is the parsed inject tag of a field, see tags.Inject for its
+// grammar.
+type injectTag = tags.Inject
+
+func parseInjectTag(f reflect.StructField) (injectTag, error) {
+	return tags.ParseInject(f.Tag.Get("inject"))
+}
+
+// checkInjectTag reports the options that don't make sense for a field of
+// type t.
+func checkInjectTag(tag injectTag, t reflect.Type) error {
+	if err := tag.Check(); err != nil {
+		return err
+	}
+	if tag.Lazy && lazyType(t) == nil {
+		return fmt.Errorf("inject tag: lazy field must be a func() T or func() (T, error), not %v", t)
+	}
+	return nil
+}
+
+// lazyType returns the type resolved by a lazy field of type t, or nil when t
+// is not a func() T or func() (T, error).
+func lazyType(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Func || t.NumIn() != 0 {
+		return nil
+	}
+	switch {
+	case t.NumOut() == 1:
+		return t.Out(0)
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+		return t.Out(0)
+	}
+	return nil
+}
+
+// tagFields lists the fields of the struct t for the params matching of the
+// tags package.
+func tagFields(t reflect.Type) []tags.Field {
+	fields := make([]tags.Field, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		fields[i] = tags.Field{Name: f.Name, Tag: f.Tag}
+	}
+	return fields
+}
+
+var tagPlans = map[reflect.Type]error{}
+
+// planTags checks the inject tags of t and of the structs it nests before
+// anything is injected. Results are cached by type.
+func planTags(t reflect.Type) error {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	stateMu.Lock()
+	err, ok := tagPlans[t]
+	stateMu.Unlock()
+	if ok {
+		return err
+	}
+	var errs []string
+	collectTagErrors(t, "", map[reflect.Type]bool{}, &errs)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		err = fmt.Errorf("%v: %s", t, strings.Join(errs, "; "))
+	}
+	stateMu.Lock()
+	tagPlans[t] = err
+	stateMu.Unlock()
+	return err
+}
+
+func collectTagErrors(t reflect.Type, path string, visited map[reflect.Type]bool, errs *[]string) {
+	if t.Kind() != reflect.Struct || visited[t] {
+		return
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Name
+		if path != "" {
+			name = path + "." + name
+		}
+		tag, err := parseInjectTag(f)
+		if err == nil {
+			err = checkInjectTag(tag, f.Type)
+		}
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("field %s: %v", name, err))
+			continue
+		}
+		if tag.Resolves() || hasDecoder(f.Type) {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		collectTagErrors(ft, name, visited, errs)
+	}
+}
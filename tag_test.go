@@ -0,0 +1,186 @@
+package inject
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseInjectTag(t *testing.T) {
+
+	type tagged struct {
+		A any `inject:"struct"`
+		B any `inject:"printer, struct, optional, name=primary, scope=request"`
+		C any `inject:"c,eager"`
+		D any `inject:"d,scope=session"`
+		E any `inject:"e,name="`
+		F any `inject:"f,optional"`
+	}
+	typ := reflect.TypeOf(tagged{})
+
+	tag, err := parseInjectTag(typ.Field(0))
+	if err != nil || tag.Alias != "" || !tag.Resolves() {
+		t.Fatalf("parseInjectTag(struct). Expected a binding without alias, got %+v, %v", tag, err)
+	}
+
+	tag, err = parseInjectTag(typ.Field(1))
+	expected := injectTag{Alias: "printer", Struct: true, Optional: true, Name: "primary", Scope: ScopeRequest}
+	if err != nil || tag != expected {
+		t.Fatalf("parseInjectTag(). Expected %+v, got %+v, %v", expected, tag, err)
+	}
+
+	for i, message := range []string{`unknown option "eager"`, `unknown scope "session"`, `option "name" has no value`} {
+		if _, err := parseInjectTag(typ.Field(i + 2)); err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("parseInjectTag(%s). Expected %s, got %v", typ.Field(i+2).Name, message, err)
+		}
+	}
+
+	tag, _ = parseInjectTag(typ.Field(5))
+	if err := checkInjectTag(tag, typ.Field(5).Type); err == nil {
+		t.Fatal("checkInjectTag(). Expected optional without struct to be rejected")
+	}
+}
+
+func TestInjectTagPlan(t *testing.T) {
+
+	type inner struct {
+		Printer iMessagePrinter `inject:"printer,scope=request"`
+	}
+	type outer struct {
+		Name  string `inject:"name" value:"outer"`
+		Inner inner
+		Lazy  iMessagePrinter `inject:"printer,lazy"`
+	}
+
+	_, err := Instanciate[outer]()
+	if err == nil {
+		t.Fatal("Instanciate(). Expected the tags to be rejected")
+	}
+	for _, message := range []string{"field Inner.Printer: inject tag: optional, name and scope need", "field Lazy: inject tag: lazy field must be a func"} {
+		if !strings.Contains(err.Error(), message) {
+			t.Fatalf("Instanciate(). Expected %q, got %v", message, err)
+		}
+	}
+}
+
+type taggedService struct {
+	Primary   iMessagePrinter                 `inject:"struct"`
+	Secondary iMessagePrinter                 `inject:"secondary,struct,name=secondary"`
+	Missing   iMessagePrinter                 `inject:"missing,struct,optional,name=missing"`
+	Shared1   *messagePrinterB                `inject:"shared1,struct,scope=request"`
+	Shared2   *messagePrinterB                `inject:"shared2,struct,scope=request"`
+	Single    *messagePrinterB                `inject:"single,struct,scope=singleton"`
+	Lazy      func() iMessagePrinter          `inject:"lazy,lazy,name=secondary"`
+	LazyError func() (iMessagePrinter, error) `inject:"lazyError,lazy,name=missing"`
+}
+
+func TestInjectTagOptions(t *testing.T) {
+
+	ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Instanciate[taggedService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+	if _, ok := s.Primary.(*messagePrinterA); !ok {
+		t.Fatalf("Primary. Expected the unnamed binding, got %T", s.Primary)
+	}
+	if s.Secondary == nil || s.Secondary.GetMessage() != "secondary printer" {
+		t.Fatalf("Secondary. Expected the named binding, got %v", s.Secondary)
+	}
+	if s.Missing != nil {
+		t.Fatalf("Missing. Expected an optional unset field, got %v", s.Missing)
+	}
+	if s.Shared1 == nil || s.Shared1 != s.Shared2 {
+		t.Fatalf("Shared. Expected one instance per request, got %p and %p", s.Shared1, s.Shared2)
+	}
+	if p := s.Lazy(); p == nil || p.GetMessage() != "secondary printer" {
+		t.Fatalf("Lazy(). Expected the named binding, got %v", p)
+	}
+	if _, err := s.LazyError(); err == nil {
+		t.Fatal("LazyError(). Expected an error for a missing named binding")
+	}
+
+	other, err := Instanciate[taggedService]()
+	if err != nil {
+		t.Fatalf("Instanciate(). Expected no error, got %v", err)
+	}
+	if other.Shared1 == s.Shared1 {
+		t.Fatal("Shared. Expected a new instance for a new request")
+	}
+	if other.Single != s.Single {
+		t.Fatal("Single. Expected the same singleton instance")
+	}
+}
+
+func TestConcurrentInstanciate(t *testing.T) {
+
+	ResetData()
+	AddInterface[iMessagePrinter]()
+	AddInjectable[messagePrinterA]()
+	AddInjectable[messagePrinterB]()
+	if err := LoadConfig("test_files/config_tags.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	type other struct {
+		Single *messagePrinterB `inject:"single,struct,scope=singleton"`
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := Instanciate[taggedService]()
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := Instanciate[other]()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Instanciate(). Expected no error, got %v", err)
+		}
+	}
+}
+
+func TestInjectTagOptionalErrors(t *testing.T) {
+
+	init_instances()
+	defer ResetData()
+	config = Config{}
+
+	type required struct {
+		Printer iMessagePrinter `inject:"struct"`
+	}
+	type optional struct {
+		Printer iMessagePrinter `inject:"printer,struct,optional"`
+	}
+
+	if _, err := Instanciate[required](); err == nil || !strings.Contains(err.Error(), "is not bound by the config") {
+		t.Fatalf("Instanciate(). Expected an unbound field error, got %v", err)
+	}
+	if s, err := Instanciate[optional](); err != nil || s.Printer != nil {
+		t.Fatalf("Instanciate(). Expected an unset optional field, got %v, %v", s, err)
+	}
+
+	// the decorators listed by the config are not registered
+	ImportConfig("test_files/config_decorators.yaml")
+	if _, err := Instanciate[optional](); err == nil || !strings.Contains(err.Error(), `decorator "metrics"`) {
+		t.Fatalf("Instanciate(). Expected the decorator error of an optional field, got %v", err)
+	}
+}
@@ -1,84 +1,290 @@
 package inject
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// Output formats of DescribeTo.
+const (
+	FormatTree = "tree" // indented text, one field per line
+	FormatJSON = "json"
+)
+
+// Placeholders written by DescribeTo instead of a value.
+const (
+	describeRedacted = "<redacted>"
+	describeCycle    = "<cycle>"
+	describeMaxDepth = "<max depth>"
+)
+
+// DescribeOptions configures DescribeTo.
+type DescribeOptions struct {
+	Format   string // FormatTree (the default) or FormatJSON
+	MaxDepth int    // levels of structs and collections written, 0 for no limit
+	Indent   string // prefix of every line but the first
+}
+
+// Describe writes v to stdout as a tree, each line prefixed by tabs.
 func Describe(v any, tabs string) {
-	describe(reflect.ValueOf(v), tabs)
+	DescribeTo(os.Stdout, v, DescribeOptions{Indent: tabs})
+}
+
+// DescribeTo writes v, usually an injected struct, to w as a tree or as JSON.
+// Fields tagged secret (like `secret:"true"`) are redacted, pointers, maps and
+// slices already being described are written as a cycle instead of followed,
+// and values nested deeper than MaxDepth are elided.
+func DescribeTo(w io.Writer, v any, opts DescribeOptions) error {
+	d := describer{opts: opts, visiting: map[visit]bool{}}
+	node := d.describe(reflect.ValueOf(v), 0)
+	switch opts.Format {
+	case "", FormatTree:
+		var sb strings.Builder
+		node.writeTree(&sb, opts.Indent)
+		_, err := io.WriteString(w, sb.String())
+		return err
+	case FormatJSON:
+		content, err := node.marshalJSON()
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, content, opts.Indent, "  "); err != nil {
+			return err
+		}
+		_, err = buf.WriteTo(w)
+		return err
+	}
+	return fmt.Errorf("describe: unknown format %q, use %s or %s", opts.Format, FormatTree, FormatJSON)
+}
+
+// visit identifies a pointer, map or slice being described. The type tells a
+// struct apart from its first field, which share the address.
+type visit struct {
+	ptr uintptr
+	t   reflect.Type
+}
+
+type describer struct {
+	opts     DescribeOptions
+	visiting map[visit]bool // pointers, maps and slices on the path from the root
 }
 
-func describe(v reflect.Value, tabs string) {
+// describeEntry is a struct field or a map entry.
+type describeEntry struct {
+	key  string
+	node *describeNode
+}
 
+// describeNode is a described value: a leaf, or the entries of a struct or
+// map, or the items of a slice or array.
+type describeNode struct {
+	label       string // type written before structs and collections in trees
+	leaf        any    // scalar or nil, when not composite
+	placeholder string // written instead of the value, like <cycle>
+	entries     []describeEntry
+	items       []*describeNode
+	object      bool
+	list        bool
+}
+
+func (d *describer) describe(v reflect.Value, depth int) *describeNode {
 	switch v.Kind() {
-	case reflect.Struct:
-		fmt.Print(tabs + v.Type().Name() + " {")
-		for i := 0; i < v.NumField(); i++ {
-			f := v.Field(i)
-			fmt.Printf("\n%s  %v: ", tabs, v.Type().Field(i).Name)
-			describe(f, tabs+"  ")
-		}
-		fmt.Print("\n" + tabs + "}")
+	case reflect.Invalid:
+		return &describeNode{}
 	case reflect.Interface:
-		fmt.Print(tabs)
-		fmt.Print(v)
 		if v.IsNil() {
-			return
+			return &describeNode{}
 		}
+		return d.describe(v.Elem(), depth)
 	case reflect.Pointer:
-		fmt.Print("*")
-		describe(v.Elem(), tabs)
-	case reflect.Array | reflect.Slice:
-
 		if v.IsNil() {
-			fmt.Print(v)
-			return
+			return &describeNode{}
 		}
-
-		fmt.Println("[")
-		for i := 0; i < v.Len(); i++ {
-			sep := ","
-			if i == v.Len()-1 {
-				sep = ""
+		key := visit{v.Pointer(), v.Type()}
+		if d.visiting[key] {
+			return &describeNode{placeholder: describeCycle}
+		}
+		d.visiting[key] = true
+		defer delete(d.visiting, key)
+		node := d.describe(v.Elem(), depth)
+		node.label = "*" + node.label
+		return node
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return &describeNode{}
 			}
-			describe(v.Index(i), tabs+"  ")
-			fmt.Println(sep)
+			// maps and slices can contain themselves through interfaces
+			key := visit{v.Pointer(), v.Type()}
+			if d.visiting[key] {
+				return &describeNode{placeholder: describeCycle}
+			}
+			d.visiting[key] = true
+			defer delete(d.visiting, key)
+		}
+		if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+			return &describeNode{label: typeLabel(v.Type()), placeholder: describeMaxDepth}
 		}
-		fmt.Print(tabs + "]")
+	}
 
+	node := &describeNode{label: typeLabel(v.Type())}
+	switch v.Kind() {
+	case reflect.Struct:
+		node.object = true
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			child := &describeNode{placeholder: describeRedacted}
+			if secret, ok := f.Tag.Lookup("secret"); !ok || secret == "false" {
+				child = d.describe(v.Field(i), depth+1)
+			}
+			node.entries = append(node.entries, describeEntry{f.Name, child})
+		}
 	case reflect.Map:
+		node.object = true
+		for _, key := range v.MapKeys() {
+			node.entries = append(node.entries, describeEntry{formatValue(key), d.describe(v.MapIndex(key), depth+1)})
+		}
+		sort.Slice(node.entries, func(i, j int) bool { return node.entries[i].key < node.entries[j].key })
+	case reflect.Slice, reflect.Array:
+		node.list = true
+		node.items = make([]*describeNode, v.Len())
+		for i := range node.items {
+			node.items[i] = d.describe(v.Index(i), depth+1)
+		}
+	default:
+		node.leaf = scalarValue(v)
+	}
+	return node
+}
 
-		if v.IsNil() {
-			fmt.Print(v)
-			return
-		}
-		fmt.Printf("%smap[%v] {", tabs, v.Kind())
-		keys := v.MapKeys()
-		for i := 0; i < len(keys); i++ {
-			key := keys[i]
-			f := v.MapIndex(key)
-			fmt.Printf("\n%s  %v: ", tabs, key)
-			describe(f, tabs+"  ")
-		}
-		fmt.Print("\n" + tabs + "}")
-
-	case reflect.Func:
-		fmt.Print(tabs)
-		fmt.Print(v)
-	case reflect.Chan:
-		fmt.Print(tabs)
-		fmt.Print(v)
-		if v.IsNil() {
-			return
+// scalarValue returns a value JSON can encode, read with the accessors of its
+// kind, which work on unexported fields too.
+func scalarValue(v reflect.Value) any {
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String()
+	case v.Kind() == reflect.Bool:
+		return v.Bool()
+	case isIntKind(v.Kind()):
+		return v.Int()
+	case isUintKind(v.Kind()):
+		return v.Uint()
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float()
+	case v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	}
+	// funcs, chans and unsafe pointers
+	if v.IsNil() {
+		return nil
+	}
+	return v.Type().String()
+}
+
+func typeLabel(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+func (n *describeNode) writeTree(sb *strings.Builder, indent string) {
+	switch {
+	case n.object:
+		sb.WriteString(n.label + " {")
+		for _, e := range n.entries {
+			sb.WriteString("\n" + indent + "  " + e.key + ": ")
+			e.node.writeTree(sb, indent+"  ")
 		}
-	case reflect.Invalid:
-		fmt.Print("'undefined'")
-		return
-	case reflect.String:
-		fmt.Print("\"", v, "\"")
+		if len(n.entries) > 0 {
+			sb.WriteString("\n" + indent)
+		}
+		sb.WriteString("}")
+	case n.list:
+		sb.WriteString(n.label + " [")
+		for _, item := range n.items {
+			sb.WriteString("\n" + indent + "  ")
+			item.writeTree(sb, indent+"  ")
+		}
+		if len(n.items) > 0 {
+			sb.WriteString("\n" + indent)
+		}
+		sb.WriteString("]")
+	case n.placeholder == describeMaxDepth:
+		sb.WriteString(n.label + " " + n.placeholder)
+	case n.placeholder != "":
+		sb.WriteString(n.placeholder)
+	case n.leaf == nil:
+		sb.WriteString("nil")
 	default:
-		fmt.Print(v)
+		if s, ok := n.leaf.(string); ok {
+			sb.WriteString(strconv.Quote(s))
+		} else {
+			fmt.Fprint(sb, n.leaf)
+		}
 	}
+}
 
+// marshalJSON writes structs and maps as objects, keeping the field order, and
+// slices and arrays as lists.
+func (n *describeNode) marshalJSON() ([]byte, error) {
+	switch {
+	case n.object:
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i, e := range n.entries {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := marshalText(e.key)
+			value, err := e.node.marshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+	case n.list:
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			value, err := item.marshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(value)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	case n.placeholder != "":
+		return marshalText(n.placeholder)
+	}
+	return marshalText(n.leaf)
+}
+
+// marshalText encodes a scalar without escaping <, > and &, used by the
+// placeholders.
+func marshalText(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
 }
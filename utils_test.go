@@ -0,0 +1,94 @@
+package inject
+
+import (
+	"strings"
+	"testing"
+)
+
+type describedNode struct {
+	Name     string
+	Password string `secret:"true"`
+	Hosts    []string
+	Ports    [2]int
+	Labels   map[string]int
+	Next     *describedNode
+	Empty    []string
+}
+
+func TestDescribeTo(t *testing.T) {
+
+	n := &describedNode{
+		Name:     "a",
+		Password: "hunter2",
+		Hosts:    []string{"h1", "h2"},
+		Ports:    [2]int{80, 443},
+		Labels:   map[string]int{"b": 2, "a": 1},
+	}
+	n.Next = n
+
+	var out strings.Builder
+	if err := DescribeTo(&out, n, DescribeOptions{}); err != nil {
+		t.Fatalf("DescribeTo(). Expected no error, got %v", err)
+	}
+	expected := `*describedNode {
+  Name: "a"
+  Password: <redacted>
+  Hosts: []string [
+    "h1"
+    "h2"
+  ]
+  Ports: [2]int [
+    80
+    443
+  ]
+  Labels: map[string]int {
+    a: 1
+    b: 2
+  }
+  Next: <cycle>
+  Empty: nil
+}`
+	if out.String() != expected {
+		t.Fatalf("DescribeTo(). Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := DescribeTo(&out, n, DescribeOptions{Format: FormatJSON, MaxDepth: 1}); err != nil {
+		t.Fatalf("DescribeTo(). Expected no error, got %v", err)
+	}
+	expected = `{
+  "Name": "a",
+  "Password": "<redacted>",
+  "Hosts": "<max depth>",
+  "Ports": "<max depth>",
+  "Labels": "<max depth>",
+  "Next": "<cycle>",
+  "Empty": null
+}`
+	if out.String() != expected {
+		t.Fatalf("DescribeTo(). Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := DescribeTo(&out, []any{1, "two", nil}, DescribeOptions{Format: FormatJSON}); err != nil || strings.Join(strings.Fields(out.String()), "") != `[1,"two",null]` {
+		t.Fatalf("DescribeTo(). Expected a JSON list, got %s, %v", out.String(), err)
+	}
+
+	m := map[string]any{"a": 1}
+	m["self"] = m
+	out.Reset()
+	if err := DescribeTo(&out, m, DescribeOptions{Format: FormatJSON}); err != nil || strings.Join(strings.Fields(out.String()), "") != `{"a":1,"self":"<cycle>"}` {
+		t.Fatalf("DescribeTo(). Expected the map cycle to be cut, got %s, %v", out.String(), err)
+	}
+
+	s := []any{1, nil}
+	s[1] = s
+	out.Reset()
+	if err := DescribeTo(&out, s, DescribeOptions{Format: FormatJSON}); err != nil || strings.Join(strings.Fields(out.String()), "") != `[1,"<cycle>"]` {
+		t.Fatalf("DescribeTo(). Expected the slice cycle to be cut, got %s, %v", out.String(), err)
+	}
+
+	if err := DescribeTo(&out, n, DescribeOptions{Format: "xml"}); err == nil {
+		t.Fatal("DescribeTo(). Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,230 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidationErrors collects every problem found by Validate.
+type ValidationErrors []error
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("inject: %d configuration problem(s):\n  %s", len(errs), strings.Join(messages, "\n  "))
+}
+
+// Validate cross-checks the imported configuration against the registered
+// interfaces, injectables and factories. It returns nil when the configuration
+// is consistent, or a ValidationErrors value listing all problems at once.
+func Validate() error {
+	var errs ValidationErrors
+	// the registry and config are read from a copy taken under the lock
+	s := Snapshot()
+	config := s.config
+
+	for _, inj := range config.Injectables {
+		if inj.Name == "" {
+			errs = append(errs, fmt.Errorf("injectables: entry in package %q has no name", inj.Package))
+			continue
+		}
+		t, err := s.injectableType(inj.ComponentPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("injectables: %w", err))
+			continue
+		}
+		if t == nil {
+			errs = append(errs, fmt.Errorf("injectables: %q is not registered (use AddInjectable)", inj.GetPath()))
+			continue
+		}
+		if inj.Params != nil {
+			errs = append(errs, validateParams(inj.GetPath(), t, inj.Params)...)
+		}
+	}
+
+	for _, inter := range config.Interfaces {
+		if inter.Name == "" {
+			errs = append(errs, fmt.Errorf("interfaces: entry in package %q has no name", inter.Package))
+			continue
+		}
+		it, err := s.interfaceType(inter.ComponentPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("interfaces: %w", err))
+		} else if it == nil {
+			errs = append(errs, fmt.Errorf("interfaces: %q is not registered (use AddInterface)", inter.GetPath()))
+		}
+		for _, name := range inter.Decorators {
+			if _, ok := s.namedDecorators[it][name]; it != nil && !ok {
+				errs = append(errs, fmt.Errorf("interfaces: %q lists decorator %q, which is not registered (use AddDecorator)", inter.GetPath(), name))
+			}
+		}
+		if inter.Injectable == "" {
+			errs = append(errs, fmt.Errorf("interfaces: %q has no injectable", inter.GetPath()))
+			continue
+		}
+		inj, err := config.BoundInjectable(&inter)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if inj == nil {
+			errs = append(errs, fmt.Errorf("interfaces: %q refers to injectable %q, which is not declared in injectables", inter.GetPath(), inter.Injectable))
+			continue
+		}
+		t, _ := s.injectableType(inj.ComponentPath)
+		if it != nil && t != nil && !reflect.PointerTo(t).Implements(it) {
+			errs = append(errs, fmt.Errorf("interfaces: injectable %q does not implement %q", inj.GetPath(), inter.GetPath()))
+		}
+	}
+
+	for _, factory := range config.Factories {
+		if factory.Name == "" {
+			errs = append(errs, fmt.Errorf("factories: entry in package %q has no name", factory.Package))
+			continue
+		}
+		t, err := s.factoryType(factory.ComponentPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("factories: %w", err))
+		} else if t == nil {
+			errs = append(errs, fmt.Errorf("factories: %q is not registered (use AddFactory)", factory.GetPath()))
+		}
+	}
+
+	types := append(registeredTypes(s.injectables), factoryTypes(s.factories)...)
+	for _, t := range sortedTypes(types) {
+		if err := planTags(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Lint checks a config on its own, without the registry: entries without
+// name or package, duplicated entries, unknown modes and interfaces bound to
+// injectables which are missing or declared in several packages. It returns
+// nil or a ValidationErrors value listing all problems.
+func (c *Config) Lint() error {
+	var errs ValidationErrors
+	check := func(section string, path ComponentPath, key string, seen map[string]bool) {
+		switch {
+		case path.Name == "":
+			errs = append(errs, fmt.Errorf("%s: entry in package %q has no name", section, path.Package))
+		case path.Package == "":
+			errs = append(errs, fmt.Errorf("%s: %q has no package", section, path.Name))
+		case seen[key]:
+			errs = append(errs, fmt.Errorf("%s: %q is declared more than once", section, key))
+		}
+		seen[key] = true
+	}
+
+	seen := map[string]bool{}
+	for _, f := range c.Factories {
+		check("factories", f.ComponentPath, f.GetPath(), seen)
+	}
+
+	seen = map[string]bool{}
+	for _, inj := range c.Injectables {
+		check("injectables", inj.ComponentPath, inj.GetPath(), seen)
+		switch inj.InjectMode {
+		case "", "auto", "interface", "factory":
+		default:
+			errs = append(errs, fmt.Errorf("injectables: %q has unknown mode %q, use auto, interface or factory", inj.GetPath(), inj.InjectMode))
+		}
+		if inj.Factory != "" && !c.hasFactory(inj.Factory) {
+			errs = append(errs, fmt.Errorf("injectables: %q refers to factory %q, which is not declared in factories", inj.GetPath(), inj.Factory))
+		}
+	}
+
+	seen = map[string]bool{}
+	for _, inter := range c.Interfaces {
+		key := inter.GetPath()
+		if inter.Named != "" {
+			key += " named " + inter.Named
+		}
+		check("interfaces", inter.ComponentPath, key, seen)
+		if inter.Injectable == "" {
+			errs = append(errs, fmt.Errorf("interfaces: %q has no injectable", inter.GetPath()))
+			continue
+		}
+		inj, err := c.BoundInjectable(&inter)
+		if err != nil {
+			errs = append(errs, err)
+		} else if inj == nil {
+			errs = append(errs, fmt.Errorf("interfaces: %q refers to injectable %q, which is not declared in injectables", inter.GetPath(), inter.Injectable))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (c *Config) hasFactory(name string) bool {
+	for _, f := range c.Factories {
+		if f.Name == name || f.GetPath() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateParams mirrors the way params are applied to a struct:
+// maps by field name, lists by field position and scalars to the first field.
+func validateParams(path string, t reflect.Type, params any) []error {
+	var errs []error
+	if t.Kind() != reflect.Struct {
+		return append(errs, fmt.Errorf("injectables: %q has params but is not a struct", path))
+	}
+	data := reflect.ValueOf(params)
+	switch data.Kind() {
+	case reflect.Slice:
+		if data.Len() > t.NumField() {
+			errs = append(errs, fmt.Errorf("injectables: %q has %d positional params but only %d fields", path, data.Len(), t.NumField()))
+		}
+		for i := 0; i < data.Len() && i < t.NumField(); i++ {
+			if err := validateParam(path, t.Field(i), data.Index(i).Interface()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	case reflect.Map:
+		iter := data.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			i, ok := fieldIndexByKey(t, key)
+			if !ok {
+				errs = append(errs, fmt.Errorf("injectables: %q params key %q does not match any field", path, key))
+				continue
+			}
+			if err := validateParam(path, t.Field(i), iter.Value().Interface()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	default:
+		if t.NumField() == 0 {
+			return append(errs, fmt.Errorf("injectables: %q has a scalar param but no fields", path))
+		}
+		if err := validateParam(path, t.Field(0), params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateParam(path string, f reflect.StructField, value any) error {
+	if tag, _ := parseInjectTag(f); !f.IsExported() && !tag.Private {
+		return fmt.Errorf("injectables: %q params field %s is not settable", path, f.Name)
+	}
+	// nested structs are not injected, which would run injection methods
+	if _, err := (&injection{checkOnly: true}).convertValue(value, f.Type); err != nil {
+		return fmt.Errorf("injectables: %q params field %s: %w", path, f.Name, err)
+	}
+	return nil
+}
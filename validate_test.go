@@ -0,0 +1,103 @@
+package inject
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateValidConfig(t *testing.T) {
+
+	init_instances()
+
+	ImportConfig("test_files/injection-config.qa.yaml")
+
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+
+}
+
+func TestValidateInvalidConfig(t *testing.T) {
+
+	init_instances()
+	AddInjectable[TestStruct]()
+
+	ImportConfig("test_files/config_invalid.yaml")
+
+	err := Validate()
+	if err == nil {
+		t.Fatalf("Validate(). Expected errors, got nil")
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate(). Expected ValidationErrors, got %T", err)
+	}
+
+	// missingContainer, Mesage, Count, messagePrinterZ, messagePrinterX,
+	// iMesagePrinter and TestStruct not implementing iMessagePrinter
+	const expected = 7
+	if len(errs) != expected {
+		t.Fatalf("Validate(). Expected %d problems, got %d:\n%v", expected, len(errs), err)
+	}
+
+	t.Log(err)
+
+}
+
+func TestValidateParamsInjectNothing(t *testing.T) {
+
+	ResetData()
+	defer ResetData()
+	AddInjectable[explainedList]()
+	AddInjectionMethods[explainedItem]()
+	config = Config{Injectables: []InjectableDescription{{
+		ComponentPath: ComponentPath{Name: "explainedList", Package: "inject"},
+		Params:        map[string]any{"Items": []any{map[string]any{"Name": "a"}}},
+	}}}
+
+	explainedInjections = 0
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate(). Expected nil, got %v", err)
+	}
+	if explainedInjections != 0 {
+		t.Fatalf("Validate(). Expected no injection method call, got %d", explainedInjections)
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+
+	c, err := ReadConfig("test_files/config_lint.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Lint()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Lint(). Expected ValidationErrors, got %v", err)
+	}
+
+	expected := []string{
+		`injectables: "inject.messagePrinterA" has unknown mode "eager"`,
+		`injectables: "inject.messagePrinterA" is declared more than once`,
+		`injectables: "messagePrinterB" has no package`,
+		`injectables: ".messagePrinterB" refers to factory "printerFactory"`,
+		`interfaces: "inject.iMessagePrinter" refers to injectable "messagePrinterX", which is not declared`,
+		`interfaces: "domain.Repository" refers to injectable "SQLRepository", which is declared in packages`,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Lint(). Expected %d problems, got %v", len(expected), err)
+	}
+	for i, message := range expected {
+		if !strings.Contains(errs[i].Error(), message) {
+			t.Fatalf("Lint(). Expected %q, got %v", message, errs[i])
+		}
+	}
+
+	c, _ = ReadConfig("test_files/injection-config.dev.yaml")
+	if err := c.Lint(); err != nil {
+		t.Fatalf("Lint(). Expected no problems, got %v", err)
+	}
+}